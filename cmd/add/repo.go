@@ -22,36 +22,32 @@ THE SOFTWARE.
 package addCmd
 
 import (
-	"fmt"
-
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // repoCmd represents the repo command
 var repoCmd = &cobra.Command{
-	Use:   "repo",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Use:   "repo <repository>...",
+	Short: "Add repositories to the list of mod and tool sources",
+	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("add repo called")
+		added, err := firestore.AddRepos(cmd.Context(), args...)
+		logger.CheckErr(err)
+
+		for _, repo := range added {
+			if viper.GetBool("dryrun") {
+				pterm.Info.Printfln("Dry run: would add %s", repo)
+			} else {
+				pterm.Success.Printfln("Added %s", repo)
+			}
+		}
 	},
 }
 
 func init() {
 	AddCmd.AddCommand(repoCmd)
-
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// repoCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// repoCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
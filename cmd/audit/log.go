@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package auditCmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// logCmd represents the audit log command
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Display audit entries, newest first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var filter firestore.AuditFilter
+		filter.Path, _ = cmd.Flags().GetString("path")
+		filter.Operator, _ = cmd.Flags().GetString("operator")
+		filter.Limit, _ = cmd.Flags().GetInt("limit")
+
+		if since, _ := cmd.Flags().GetString("since"); since != "" {
+			t, err := parseSince(since)
+			logger.CheckErr(err)
+			filter.Since = t
+		}
+
+		entries, err := firestore.AuditLog(cmd.Context(), filter)
+		logger.CheckErr(err)
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			out, err := json.MarshalIndent(entries, "", "  ")
+			logger.CheckErr(err)
+			fmt.Println(string(out))
+			return
+		}
+
+		printEntries(entries)
+	},
+}
+
+func init() {
+	logCmd.Flags().String("path", "", "only show entries for this document path (e.g. mods/abc123)")
+	logCmd.Flags().String("since", "", "only show entries since a time (RFC 3339, YYYY-MM-DD) or age (e.g. 36h, 7d)")
+	logCmd.Flags().String("operator", "", "only show entries made by this operator")
+	logCmd.Flags().Int("limit", 50, "maximum number of entries to show (0 for all)")
+	logCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+
+	AuditCmd.AddCommand(logCmd)
+}
+
+// parseSince accepts a timestamp or an age such as "36h" or "7d"
+func parseSince(s string) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	return models.ParseTime(s)
+}
+
+func printEntries(entries []firestore.AuditEntry) {
+	if len(entries) == 0 {
+		pterm.Info.Println("No audit entries found")
+		return
+	}
+
+	for _, entry := range entries {
+		pterm.DefaultSection.WithLevel(2).Printfln("%s  %s  %s", entry.Timestamp.Local().Format(time.DateTime), entry.Operator, entry.Path)
+		pterm.Println(pterm.Gray(entry.Command))
+
+		for _, change := range entry.Changes {
+			if change.Before != nil {
+				pterm.FgRed.Printfln("- %s: %v", change.Field, change.Before)
+			}
+			if change.After != nil {
+				pterm.FgGreen.Printfln("+ %s: %v", change.Field, change.After)
+			}
+		}
+	}
+}
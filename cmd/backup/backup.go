@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package backupCmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/backup"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// BackupCmd represents the backup command
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Export the whole database to a local archive",
+	Long: `Exports every configured collection and meta document to a timestamped tar.gz of JSON documents.
+
+The archive includes a manifest with document counts, SHA-256 checksums, the tool version
+and the source project ID, which "pdt restore" verifies before restoring anything.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			output = backup.Filename(firestore.ProjectID(), time.Now())
+		}
+
+		snap, err := backup.Live(cmd.Context())
+		logger.CheckErr(err)
+
+		manifest, err := backup.WriteArchive(output, snap, cmd.Root().Version, firestore.ProjectID())
+		logger.CheckErr(err)
+
+		printManifest(manifest)
+		pterm.Success.Printfln("Backed up %d documents to %s", len(manifest.Checksums), output)
+	},
+}
+
+func init() {
+	BackupCmd.Flags().StringP("output", "o", "", "archive to write (default <project>-backup-<timestamp>.tar.gz)")
+}
+
+func printManifest(manifest *backup.Manifest) {
+	collections := make([]string, 0, len(manifest.Counts))
+	for c := range manifest.Counts {
+		collections = append(collections, c)
+	}
+	sort.Strings(collections)
+
+	data := pterm.TableData{{"Collection", "Documents"}}
+	for _, c := range collections {
+		data = append(data, []string{c, fmt.Sprint(manifest.Counts[c])})
+	}
+
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Donovan C. Young
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package checkCmd
+
+import (
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/syncer"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// reposCmd represents the check repos command
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "Find repositories that were renamed, transferred, archived or deleted",
+	Long: `Looks up every repository in meta/repos on GitHub and reports the ones that were renamed,
+transferred, archived, disabled or deleted.
+
+With --fix, moved repositories are rewritten to their new location (in meta/repos and in the
+modinfo and toolinfo lists), and archived, disabled and deleted repositories are moved to the
+inactive list. Their modinfo and toolinfo URLs are dropped so sync no longer reads them.
+All lists are updated in a single transaction.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fix, _ := cmd.Flags().GetBool("fix")
+
+		health, err := syncer.CheckRepos(cmd.Context())
+		logger.CheckErr(err)
+
+		problems := 0
+		for _, h := range health {
+			if h.Status == syncer.RepoOK {
+				continue
+			}
+			problems++
+			pterm.Warning.Println(h)
+		}
+		if problems == 0 {
+			pterm.Success.Printfln("All %d repositories are healthy", len(health))
+			return
+		}
+		if !fix {
+			pterm.Info.Printfln("%d of %d repositories need attention; run with --fix to update them", problems, len(health))
+			return
+		}
+
+		changed, err := syncer.FixRepos(cmd.Context(), health)
+		logger.CheckErr(err)
+		if viper.GetBool("dryrun") {
+			pterm.Info.Printfln("Dry run: would update %d repositories", changed)
+		} else {
+			pterm.Success.Printfln("Updated %d repositories", changed)
+		}
+	},
+}
+
+func init() {
+	reposCmd.Flags().Bool("fix", false, "rewrite moved repositories and mark archived or deleted ones inactive")
+	CheckCmd.AddCommand(reposCmd)
+}
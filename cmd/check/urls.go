@@ -0,0 +1,152 @@
+/*
+Copyright © 2025 Donovan C. Young
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package checkCmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/linkcheck"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// problem is a link that is broken, permanently redirected, slow, or a file that looks wrong
+type problem struct {
+	linkcheck.Link
+	Problem string           `json:"problem"`
+	Result  linkcheck.Result `json:"result"`
+}
+
+// urlsCmd represents the check urls command
+var urlsCmd = &cobra.Command{
+	Use:   "urls",
+	Short: "Check that every file, image and readme URL is reachable",
+	Long: `Checks every file, image and readme URL of every mod and tool, and reports broken,
+permanently redirected and slow links grouped by author, along with files that are
+empty or served as an HTML page.
+
+Each URL is checked with HEAD, falling back to a ranged GET for servers that reject HEAD.
+Results are cached for --cache-ttl, so repeated runs only check what has expired.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		perHost, _ := cmd.Flags().GetInt("per-host")
+		maxRedirects, _ := cmd.Flags().GetInt("max-redirects")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		slow, _ := cmd.Flags().GetDuration("slow")
+		ttl, _ := cmd.Flags().GetDuration("cache-ttl")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		links, err := linkcheck.Links(cmd.Context())
+		logger.CheckErr(err)
+
+		var cache *linkcheck.Cache
+		if ttl > 0 {
+			file, err := linkcheck.DefaultCacheFile()
+			logger.CheckErr(err)
+			cache, err = linkcheck.OpenCache(file, ttl)
+			logger.CheckErr(err)
+		}
+
+		checker := linkcheck.New(linkcheck.Options{
+			Concurrency:  concurrency,
+			PerHost:      perHost,
+			MaxRedirects: maxRedirects,
+			Timeout:      timeout,
+			Cache:        cache,
+		})
+		results := checker.CheckAll(cmd.Context(), linkcheck.URLs(links))
+		if err := cache.Save(); err != nil {
+			logger.Log.Warn(fmt.Sprintf("Unable to save the link check cache: %v", err))
+		}
+
+		var problems []problem
+		broken := 0
+		for _, l := range links {
+			r := results[l.URL]
+			p := r.Problem(slow)
+			if fileType, ok := strings.CutPrefix(l.Field, "files."); ok && p == "" {
+				p = models.FileProblem(fileType, r.ContentLength, r.ContentType)
+			}
+			if p != "" {
+				problems = append(problems, problem{Link: l, Problem: p, Result: r})
+				if r.Broken() {
+					broken++
+				}
+			}
+		}
+
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			logger.CheckErr(enc.Encode(problems))
+		} else {
+			printProblems(problems)
+			pterm.Info.Printfln("Checked %d URLs in %d links: %d problem(s), %d broken", len(results), len(links), len(problems), broken)
+		}
+
+		if broken > 0 {
+			logger.CheckErr(fmt.Errorf("%d broken link(s)", broken))
+		}
+	},
+}
+
+func init() {
+	urlsCmd.Flags().Int("concurrency", 16, "number of URLs checked at once")
+	urlsCmd.Flags().Int("per-host", 4, "number of URLs checked at once on any single host")
+	urlsCmd.Flags().Int("max-redirects", 5, "redirects to follow before a link counts as broken")
+	urlsCmd.Flags().Duration("timeout", 15*time.Second, "timeout for each request")
+	urlsCmd.Flags().Duration("slow", 5*time.Second, "report links slower than this")
+	urlsCmd.Flags().Duration("cache-ttl", time.Hour, "reuse results checked within this long (0 disables the cache)")
+	urlsCmd.Flags().Bool("json", false, "print the problems as JSON")
+	CheckCmd.AddCommand(urlsCmd)
+}
+
+// printProblems shows a table of problems per author
+func printProblems(problems []problem) {
+	byAuthor := make(map[string][]problem)
+	for _, p := range problems {
+		byAuthor[p.Author] = append(byAuthor[p.Author], p)
+	}
+
+	authors := make([]string, 0, len(byAuthor))
+	for author := range byAuthor {
+		authors = append(authors, author)
+	}
+	sort.Slice(authors, func(i, j int) bool { return strings.ToLower(authors[i]) < strings.ToLower(authors[j]) })
+
+	for _, author := range authors {
+		pterm.DefaultSection.Println(author)
+
+		data := pterm.TableData{{"Entry", "Field", "URL", "Problem"}}
+		for _, p := range byAuthor[author] {
+			data = append(data, []string{fmt.Sprintf("%s %s (%s)", p.Kind, p.Name, p.ID), p.Field, p.URL, p.Problem})
+		}
+		_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package configCmd
+
+import (
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd represents the config command
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Create and inspect the pdt configuration",
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{initCmd, showCmd, validateCmd} {
+		cmd.Annotations = map[string]string{config.AnnotationNoConfig: "true"}
+		ConfigCmd.AddCommand(cmd)
+	}
+}
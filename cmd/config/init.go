@@ -0,0 +1,234 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package configCmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/github"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// initCmd represents the config init command
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Interactively create a config file",
+	Long: `Asks for the Firebase credentials file, project ID, collection paths and GitHub token,
+tests that they work, and writes the config file (~/.pdtconfig.json by default) readable only by you.
+
+Leave the credentials file blank to use Application Default Credentials.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("config")
+		if file == "" {
+			home, err := os.UserHomeDir()
+			logger.CheckErr(err)
+			file = filepath.Join(home, ".pdtconfig.json")
+		}
+
+		logger.CheckErr(initConfig(cmd.Context(), file))
+	},
+}
+
+func initConfig(ctx context.Context, file string) error {
+	if _, err := os.Stat(file); err == nil {
+		overwrite, _ := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("%s already exists. Overwrite it?", file))
+		if !overwrite {
+			return errors.New("config init cancelled")
+		}
+	}
+
+	pterm.DefaultSection.Println("Firebase")
+
+	credentialsFile, err := ask("Service account credentials file (blank for Application Default Credentials)", "")
+	if err != nil {
+		return err
+	}
+
+	// Application Default Credentials name their project themselves, so there is no file to read it from
+	var detectedProjectID string
+	if credentialsFile != "" {
+		credentialsFile = config.ExpandHome(credentialsFile)
+		if detectedProjectID, err = readProjectID(credentialsFile); err != nil {
+			return err
+		}
+	}
+
+	projectID, err := ask("Project ID", detectedProjectID)
+	if err != nil {
+		return err
+	}
+
+	pterm.DefaultSection.Println("Collections")
+
+	collections := make(map[string]any)
+	keys := make([]string, 0, len(config.DefaultCollections))
+	for k := range config.DefaultCollections {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		path, err := ask(key, config.DefaultCollections[key])
+		if err != nil {
+			return err
+		}
+		setNested(collections, key, path)
+	}
+
+	pterm.DefaultSection.Println("GitHub")
+
+	token, err := pterm.DefaultInteractiveTextInput.WithMask("*").Show("GitHub access token (optional)")
+	if err != nil {
+		return err
+	}
+	token = strings.TrimSpace(token)
+
+	firebase := map[string]any{"collections": collections}
+	if credentialsFile != "" {
+		firebase["credentials_file"] = credentialsFile
+	}
+	if projectID != "" {
+		firebase["project_id"] = projectID
+	}
+
+	settings := map[string]any{"firebase": firebase}
+	if token != "" {
+		settings["github"] = map[string]any{"token": token}
+	}
+
+	if err := testConnectivity(ctx, settings, token); err != nil {
+		pterm.Error.Println(err)
+		if save, _ := pterm.DefaultInteractiveConfirm.Show("Save the config anyway?"); !save {
+			return errors.New("config init cancelled")
+		}
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	// WriteFile only applies the permissions to new files
+	if err := os.Chmod(file, 0o600); err != nil {
+		return err
+	}
+
+	pterm.Success.Printfln("Wrote %s", file)
+	return nil
+}
+
+// testConnectivity checks the Firestore credentials and, when given, the GitHub token
+func testConnectivity(ctx context.Context, settings map[string]any, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Test only the new answers, so credentials already in the loaded config can't stand in for them
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return err
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Connecting to Firestore...")
+	if err := firestore.Ping(ctx, v); err != nil {
+		spinner.Fail("Unable to connect to Firestore")
+		return err
+	}
+	spinner.Success("Connected to Firestore")
+
+	if token == "" {
+		return nil
+	}
+
+	spinner, _ = pterm.DefaultSpinner.Start("Checking GitHub token...")
+	gh, err := github.NewClient(viper.GetString("github.base_url"), viper.GetString("github.raw_url"), token, nil)
+	if err != nil {
+		spinner.Fail("Invalid GitHub URL")
+		return err
+	}
+
+	user, err := gh.CurrentUser(ctx)
+	if err != nil {
+		spinner.Fail("GitHub rejected the token")
+		return fmt.Errorf("GitHub token check failed: %w", err)
+	}
+	spinner.Success(fmt.Sprintf("GitHub token is valid (%s)", user.Login))
+
+	return nil
+}
+
+func ask(prompt, defaultValue string) (string, error) {
+	input := pterm.DefaultInteractiveTextInput
+	if defaultValue != "" {
+		input = *input.WithDefaultValue(defaultValue)
+	}
+
+	answer, err := input.Show(prompt)
+	return strings.TrimSpace(answer), err
+}
+
+// readProjectID returns the project_id from a service account file
+func readProjectID(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	var creds struct {
+		ProjectID string `json:"project_id"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return "", fmt.Errorf("%s is not a credentials JSON file: %w", file, err)
+	}
+
+	return creds.ProjectID, nil
+}
+
+// setNested sets a dotted key (e.g. "meta.modinfo") in a nested map
+func setNested(m map[string]any, key, value string) {
+	parent, leaf, ok := strings.Cut(key, ".")
+	if !ok {
+		m[key] = value
+		return
+	}
+
+	child, _ := m[parent].(map[string]any)
+	if child == nil {
+		child = make(map[string]any)
+		m[parent] = child
+	}
+	setNested(child, leaf, value)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package configCmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// showCmd represents the config show command
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration and where each setting came from",
+	Long: `Prints every effective setting after the config file, profile, environment variables and flags
+have been merged, along with the source that supplied it. Private keys and tokens are redacted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		settings := config.Effective(cmd)
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			out, err := json.MarshalIndent(settings, "", "  ")
+			logger.CheckErr(err)
+			fmt.Println(string(out))
+			return
+		}
+
+		if profile := config.Profile(); profile != "" {
+			pterm.Info.Printfln("Active profile: %s", profile)
+		}
+
+		data := pterm.TableData{{"Key", "Value", "Source"}}
+		for _, s := range settings {
+			data = append(data, []string{s.Key, fmt.Sprint(s.Value), s.Source})
+		}
+		_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	},
+}
+
+func init() {
+	showCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+}
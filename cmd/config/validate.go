@@ -0,0 +1,55 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package configCmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the config validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration for unknown and missing keys",
+	Long:  `Reports unknown keys and missing required keys, and exits with a non-zero status if any are found.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		errs := config.Validate()
+		if len(config.Files()) == 0 {
+			errs = append([]error{errors.New("no config file found")}, errs...)
+		}
+
+		for _, err := range errs {
+			pterm.Error.Println(err)
+		}
+		if len(errs) > 0 {
+			logger.CheckErr(fmt.Errorf("configuration has %d problem(s)", len(errs)))
+		}
+
+		pterm.Success.Println("Configuration is valid")
+	},
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package diffCmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/backup"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// DiffCmd represents the diff command
+var DiffCmd = &cobra.Command{
+	Use:   "diff <a> <b>",
+	Short: "Compare two snapshots of the database",
+	Long: `Reports the documents that were added, removed or changed going from <a> to <b>, with field-level detail.
+
+Each side can be a backup archive, a local directory of JSON documents (laid out as <collection>/<id>.json,
+such as an extracted backup) or "live" for the live database. For example:
+
+  pdt diff pdt-backup-20251014.tar.gz live`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		collections, _ := cmd.Flags().GetStringSlice("collections")
+
+		a, err := backup.Open(ctx, args[0])
+		logger.CheckErr(err)
+		b, err := backup.Open(ctx, args[1])
+		logger.CheckErr(err)
+
+		changes := backup.Compare(a.Filter(collections), b.Filter(collections))
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if changes == nil {
+				changes = []backup.DocumentChange{}
+			}
+			out, err := json.MarshalIndent(changes, "", "  ")
+			logger.CheckErr(err)
+			fmt.Println(string(out))
+			return
+		}
+
+		printChanges(changes)
+	},
+}
+
+func init() {
+	DiffCmd.Flags().StringSlice("collections", nil, "only compare these collections (e.g. mods,tools)")
+	DiffCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+}
+
+func printChanges(changes []backup.DocumentChange) {
+	if len(changes) == 0 {
+		pterm.Info.Println("No differences")
+		return
+	}
+
+	var added, removed, changed int
+	for _, c := range changes {
+		switch c.Status {
+		case backup.Added:
+			added++
+			pterm.FgGreen.Printfln("+ %s", c.Path)
+		case backup.Removed:
+			removed++
+			pterm.FgRed.Printfln("- %s", c.Path)
+		case backup.Changed:
+			changed++
+			pterm.FgYellow.Printfln("~ %s", c.Path)
+			for _, f := range c.Changes {
+				if f.Before != nil {
+					pterm.FgRed.Printfln("    - %s: %v", f.Field, f.Before)
+				}
+				if f.After != nil {
+					pterm.FgGreen.Printfln("    + %s: %v", f.Field, f.After)
+				}
+			}
+		}
+	}
+
+	pterm.Info.Printfln("%d added, %d removed, %d changed", added, removed, changed)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright © 2025 Donovan C. Young
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package discoverCmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/syncer"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// DiscoverCmd represents the discover command
+var DiscoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Find candidate mod and tool repositories on GitHub",
+	Long: `Searches GitHub for repositories by topic and, when github.token is set, for repositories
+containing modinfo.json or toolinfo.json. Repositories already listed in meta/repos, active or
+inactive, are left out, as are the repositories of hidden or blocked mods and tools.
+
+Each candidate is shown with its stars, last push and a preview of its info files as sync would
+read them. The selected candidates are then added to meta/repos.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		topics, _ := cmd.Flags().GetStringSlice("topic")
+		codeSearch, _ := cmd.Flags().GetBool("code-search")
+		limit, _ := cmd.Flags().GetInt("limit")
+		list, _ := cmd.Flags().GetBool("list")
+
+		candidates, err := syncer.Candidates(cmd.Context(), syncer.DiscoverOptions{Topics: topics, CodeSearch: codeSearch, Limit: limit})
+		logger.CheckErr(err)
+
+		if len(candidates) == 0 {
+			pterm.Info.Println("No new repositories found")
+			return
+		}
+		printCandidates(candidates)
+		if list {
+			return
+		}
+
+		options := make([]string, len(candidates))
+		for i, c := range candidates {
+			options[i] = c.Repo.FullName
+		}
+		selected, err := pterm.DefaultInteractiveMultiselect.WithOptions(options).WithMaxHeight(15).Show("Select repositories to add")
+		logger.CheckErr(err)
+		if len(selected) == 0 {
+			pterm.Info.Println("Nothing added")
+			return
+		}
+
+		urls := make([]string, 0, len(selected))
+		for _, c := range candidates {
+			for _, name := range selected {
+				if c.Repo.FullName == name {
+					urls = append(urls, c.Repo.HTMLURL)
+				}
+			}
+		}
+
+		added, err := firestore.AddRepos(cmd.Context(), urls...)
+		logger.CheckErr(err)
+		for _, repo := range added {
+			if viper.GetBool("dryrun") {
+				pterm.Info.Printfln("Dry run: would add %s", repo)
+			} else {
+				pterm.Success.Printfln("Added %s", repo)
+			}
+		}
+	},
+}
+
+func init() {
+	DiscoverCmd.Flags().StringSlice("topic", []string{"icarus-mod"}, "repository topics to search for")
+	DiscoverCmd.Flags().Bool("code-search", true, "also search for repositories containing modinfo.json or toolinfo.json (requires github.token)")
+	DiscoverCmd.Flags().Int("limit", 50, "maximum results per search")
+	DiscoverCmd.Flags().Bool("list", false, "only list the candidates, without offering to add them")
+}
+
+func printCandidates(candidates []syncer.Candidate) {
+	data := pterm.TableData{{"Repository", "Stars", "Last push", "Found by", "Preview"}}
+	for _, c := range candidates {
+		pushed := "-"
+		if !c.Repo.PushedAt.IsZero() {
+			pushed = c.Repo.PushedAt.Local().Format(time.DateOnly)
+		}
+
+		data = append(data, []string{
+			c.Repo.FullName,
+			fmt.Sprint(c.Repo.StargazersCount),
+			pushed,
+			strings.Join(c.Matched, ", "),
+			describe(c.Previews),
+		})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+
+	for _, c := range candidates {
+		for _, p := range c.Previews {
+			for _, invalid := range p.Invalid {
+				pterm.Warning.Printfln("%s %s: %s", c.Repo.FullName, p.File, invalid)
+			}
+		}
+	}
+}
+
+// describe summarizes the info files of a candidate in a single table cell
+func describe(previews []syncer.Preview) string {
+	if len(previews) == 0 {
+		return "no info files"
+	}
+
+	parts := make([]string, 0, len(previews))
+	for _, p := range previews {
+		switch {
+		case p.Err != nil:
+			parts = append(parts, fmt.Sprintf("%s: unreadable (%v)", p.File, p.Err))
+		case len(p.Invalid) > 0:
+			parts = append(parts, fmt.Sprintf("%s: %d %s(s), %d invalid", p.File, p.Entries, p.Kind, len(p.Invalid)))
+		default:
+			parts = append(parts, fmt.Sprintf("%s: %d %s(s)", p.File, p.Entries, p.Kind))
+		}
+	}
+
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,182 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package editCmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/diff"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// EditCmd represents the edit command
+var EditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit a mod or tool document in your $EDITOR",
+	Long: `Opens a mod or tool document as YAML in $VISUAL or $EDITOR.
+
+When the editor exits, the document is validated and a diff of the changes is shown for confirmation.
+The changes are only written if the document has not been modified (e.g. by a sync or another moderator)
+since it was opened.`,
+}
+
+// edit opens the document in the user's editor and writes back any confirmed changes
+func edit(ctx context.Context, kind, id string) error {
+	before, updated, err := firestore.GetEntry(ctx, kind, id)
+	if err != nil {
+		return err
+	}
+
+	original, err := yaml.Marshal(before)
+	if err != nil {
+		return err
+	}
+
+	var after models.Document
+	content := original
+	for {
+		content, err = openEditor(fmt.Sprintf("pdt-%s-%s-*.yaml", kind, id), content)
+		if err != nil {
+			return err
+		}
+
+		// Decode into a fresh document each time so fields removed on a retry don't keep earlier values
+		if after, err = models.New(kind); err != nil {
+			return err
+		}
+		if err = decode(content, after); err == nil {
+			break
+		}
+
+		pterm.Error.Printfln("Invalid %s:\n%v", kind, err)
+		if retry, _ := pterm.DefaultInteractiveConfirm.WithDefaultValue(true).Show("Re-open the editor?"); !retry {
+			return fmt.Errorf("%s %q was not changed", kind, id)
+		}
+	}
+
+	lines := diff.Lines(string(original), string(content))
+	if !diff.Changed(lines) {
+		pterm.Info.Printfln("No changes made to %s %q", kind, id)
+		return nil
+	}
+	printDiff(lines)
+
+	if viper.GetBool("dryrun") {
+		pterm.Info.Println("Dry run: changes were not written")
+		return nil
+	}
+
+	if ok, _ := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("Write these changes to %s %q?", kind, id)); !ok {
+		pterm.Info.Println("Changes discarded")
+		return nil
+	}
+
+	if err := firestore.UpdateEntry(ctx, kind, id, before, after, updated); err != nil {
+		if errors.Is(err, firestore.ErrConflict) {
+			return fmt.Errorf("%w; re-run the edit to start from the current version", err)
+		}
+		return err
+	}
+
+	pterm.Success.Printfln("Updated %s %q", kind, id)
+	return nil
+}
+
+// decode strictly parses the edited YAML into doc and validates the result
+func decode(content []byte, doc models.Document) error {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(doc); err != nil {
+		return err
+	}
+
+	return doc.Validate()
+}
+
+// openEditor writes content to a temporary file, opens it in the user's editor and returns the edited content
+func openEditor(pattern string, content []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	args := append(strings.Fields(editor()), f.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q failed: %w", args[0], err)
+	}
+
+	return os.ReadFile(f.Name())
+}
+
+// editor returns the user's preferred editor command
+func editor() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(env)); e != "" {
+			return e
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+func printDiff(lines []diff.Line) {
+	for _, l := range lines {
+		switch l.Op {
+		case '+':
+			pterm.FgGreen.Println(l.String())
+		case '-':
+			pterm.FgRed.Println(l.String())
+		default:
+			fmt.Println(l.String())
+		}
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package migrateCmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/backup"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/migrate"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// MigrateCmd represents the migrate command
+var MigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply or revert versioned schema migrations",
+	Long: `Migrations are registered in code and applied in version order. The current schema version
+is stored as schemaVersion in the meta/status document.
+
+Each migration runs in its own transaction together with the version update, and a backup of the
+whole database is written before any migration is applied or reverted. Use --dryrun to preview
+the writes without applying them.`,
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the current schema version and pending migrations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		current, err := migrate.Current(cmd.Context())
+		logger.CheckErr(err)
+
+		data := pterm.TableData{{"Version", "Name", "State"}}
+		for _, m := range migrate.Migrations() {
+			state := "pending"
+			if m.Version <= current {
+				state = "applied"
+			}
+			data = append(data, []string{fmt.Sprint(m.Version), m.Name, state})
+		}
+
+		pterm.Info.Printfln("Schema version %d (latest %d)", current, migrate.Latest())
+		_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	},
+}
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply pending migrations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		target, _ := cmd.Flags().GetInt("to")
+		run(cmd, func(ctx context.Context, dryrun bool) ([]migrate.Step, error) {
+			return migrate.Up(ctx, target, dryrun)
+		})
+	},
+}
+
+var downCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert applied migrations (the latest one by default)",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		target, _ := cmd.Flags().GetInt("to")
+		run(cmd, func(ctx context.Context, dryrun bool) ([]migrate.Step, error) {
+			return migrate.Down(ctx, target, dryrun)
+		})
+	},
+}
+
+func init() {
+	upCmd.Flags().Int("to", 0, "migrate up to this version (default latest)")
+	downCmd.Flags().Int("to", -1, "revert down to this version (default reverts the latest applied migration)")
+	MigrateCmd.PersistentFlags().String("backup-dir", ".", "directory for the automatic pre-migration backup")
+
+	MigrateCmd.AddCommand(statusCmd)
+	MigrateCmd.AddCommand(upCmd)
+	MigrateCmd.AddCommand(downCmd)
+}
+
+// run takes a backup (unless this is a dry run), then performs the migration steps and reports them
+func run(cmd *cobra.Command, migrateFn func(ctx context.Context, dryrun bool) ([]migrate.Step, error)) {
+	ctx := cmd.Context()
+	dryrun := viper.GetBool("dryrun")
+
+	if !dryrun {
+		dir, _ := cmd.Flags().GetString("backup-dir")
+		file := filepath.Join(dir, backup.Filename(firestore.ProjectID(), time.Now()))
+
+		snap, err := backup.Live(ctx)
+		logger.CheckErr(err)
+		_, err = backup.WriteArchive(file, snap, cmd.Root().Version, firestore.ProjectID())
+		logger.CheckErr(err)
+		pterm.Info.Printfln("Backed up the database to %s", file)
+	}
+
+	steps, err := migrateFn(ctx, dryrun)
+	for _, step := range steps {
+		verb := "Applied"
+		switch {
+		case dryrun && step.Direction == "up":
+			verb = "Dry run: would apply"
+		case dryrun:
+			verb = "Dry run: would revert"
+		case step.Direction == "down":
+			verb = "Reverted"
+		}
+
+		pterm.Success.Printfln("%s migration %d (%s): %d document write(s)", verb, step.Migration.Version, step.Migration.Name, len(step.Writes))
+		if dryrun {
+			for _, w := range step.Writes {
+				pterm.Println("  " + w.Path)
+			}
+		}
+	}
+	logger.CheckErr(err)
+
+	if len(steps) == 0 {
+		pterm.Info.Println("Nothing to migrate")
+	}
+}
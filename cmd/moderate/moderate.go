@@ -0,0 +1,98 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package moderateCmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/operator"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ModerateCmd represents the moderate command
+var ModerateCmd = &cobra.Command{
+	Use:   "moderate",
+	Short: "Hide, feature or block mods and tools",
+	Long: `Sets the moderation fields of a mod or tool document.
+
+Every action records a reason, the moderator and a timestamp. Moderation fields are kept
+when sync rewrites a document, and blocked entries are never re-created by sync.
+Use "pdt set <kind> <id> moderation.blocked=false" to lift a block.`,
+}
+
+var descriptions = map[string]string{
+	models.ActionHide:      "Hide a mod or tool from the catalog",
+	models.ActionUnhide:    "Show a previously hidden mod or tool",
+	models.ActionFeature:   "Feature a mod or tool",
+	models.ActionUnfeature: "Stop featuring a mod or tool",
+	models.ActionBlock:     "Block a mod or tool so sync never re-creates it",
+}
+
+func init() {
+	for _, action := range models.ModerationActions {
+		cmd := &cobra.Command{
+			Use:   action + " <id>",
+			Short: descriptions[action],
+			Args:  cobra.ExactArgs(1),
+			Run: func(cmd *cobra.Command, args []string) {
+				reason, _ := cmd.Flags().GetString("reason")
+				logger.CheckErr(moderate(cmd.Context(), action, args[0], reason))
+			},
+		}
+		cmd.Flags().StringP("reason", "r", "", "reason for the moderation action (required)")
+		_ = cmd.MarkFlagRequired("reason")
+
+		ModerateCmd.AddCommand(cmd)
+	}
+}
+
+func moderate(ctx context.Context, action, id, reason string) error {
+	kind, before, updated, err := firestore.FindEntry(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	after, err := models.Clone(before)
+	if err != nil {
+		return err
+	}
+	after.Moderated().Apply(action, reason, operator.Name(), time.Now().UTC())
+
+	if viper.GetBool("dryrun") {
+		pterm.Info.Printfln("Dry run: would %s %s %q", action, kind, id)
+		return nil
+	}
+
+	if err := firestore.UpdateEntry(ctx, kind, id, before, after, updated); err != nil {
+		return err
+	}
+
+	pterm.Success.Println(fmt.Sprintf("%s %q: %s", kind, id, action))
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package restoreCmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/backup"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// RestoreCmd represents the restore command
+var RestoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore documents from a backup archive",
+	Long: `Restores documents from an archive created by "pdt backup".
+
+The archive is verified against its manifest checksums, and a per-collection diff against the
+live database is shown before anything is written. Documents that exist in the live database
+but not in the archive are never deleted.
+
+Conflict policies decide what happens to documents that exist in both:
+  overwrite      replace live documents that differ from the archive
+  skip-existing  leave existing documents untouched, only create missing ones
+  only-missing   create missing documents and add missing fields, never changing a live value`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		collections, _ := cmd.Flags().GetStringSlice("collections")
+		policy, _ := cmd.Flags().GetString("policy")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		logger.CheckErr(restore(cmd.Context(), args[0], collections, policy, yes))
+	},
+}
+
+func init() {
+	RestoreCmd.Flags().StringSlice("collections", nil, "only restore these collections (e.g. mods,tools,meta)")
+	RestoreCmd.Flags().String("policy", backup.SkipExisting, fmt.Sprintf("conflict policy (%s)", strings.Join(backup.Policies, ", ")))
+	RestoreCmd.Flags().BoolP("yes", "y", false, "do not ask for confirmation")
+}
+
+func restore(ctx context.Context, file string, collections []string, policy string, yes bool) error {
+	archive, manifest, err := backup.ReadArchive(file)
+	if err != nil {
+		return err
+	}
+	pterm.Info.Printfln("Verified %s: %d documents from %q, created %s by pdt v%s",
+		file, len(manifest.Checksums), manifest.ProjectID, manifest.CreatedAt.Local().Format(time.DateTime), manifest.ToolVersion)
+
+	for _, c := range collections {
+		if !slices.Contains(archive.Collections(), c) {
+			return fmt.Errorf("collection %q is not in the archive (available: %s)", c, strings.Join(archive.Collections(), ", "))
+		}
+	}
+	archive = archive.Filter(collections)
+
+	live, err := backup.LivePaths(ctx, archive.Collections())
+	if err != nil {
+		return err
+	}
+
+	printDiff(archive, live)
+
+	actions, err := backup.PlanRestore(archive, live, policy)
+	if err != nil {
+		return err
+	}
+	if len(actions) == 0 {
+		pterm.Info.Println("Nothing to restore")
+		return nil
+	}
+
+	if projectID := firestore.ProjectID(); manifest.ProjectID != projectID {
+		pterm.Warning.Printfln("This archive was taken from %q but the active project is %q", manifest.ProjectID, projectID)
+	}
+
+	if viper.GetBool("dryrun") {
+		for _, a := range actions {
+			pterm.Info.Printfln("Dry run: would %s %s", describe(a), a.Path)
+		}
+		return nil
+	}
+
+	if !yes {
+		question := fmt.Sprintf("Restore %d document(s) using the %s policy?", len(actions), policy)
+		if ok, _ := pterm.DefaultInteractiveConfirm.Show(question); !ok {
+			pterm.Info.Println("Restore cancelled")
+			return nil
+		}
+	}
+
+	applied, err := backup.Restore(ctx, actions)
+	if err != nil {
+		for _, a := range actions[:applied] {
+			pterm.Info.Printfln("Restored %s", a.Path)
+		}
+		return fmt.Errorf("restore stopped after %d of %d document(s): %w", applied, len(actions), err)
+	}
+
+	pterm.Success.Printfln("Restored %d document(s)", len(actions))
+	return nil
+}
+
+// printDiff shows how the archive differs from the live database, per collection
+func printDiff(archive, live *backup.Snapshot) {
+	type counts struct{ missing, extra, changed int }
+	byCollection := make(map[string]*counts)
+	for _, c := range archive.Collections() {
+		byCollection[c] = &counts{}
+	}
+
+	for _, change := range backup.Compare(live, archive) {
+		c := byCollection[backup.Collection(change.Path)]
+		switch change.Status {
+		case backup.Added:
+			c.missing++
+		case backup.Removed:
+			c.extra++
+		case backup.Changed:
+			c.changed++
+		}
+	}
+
+	archived, current := archive.Counts(), live.Counts()
+	data := pterm.TableData{{"Collection", "Archive", "Live", "Missing from live", "Only in live", "Changed"}}
+	for _, name := range archive.Collections() {
+		c := byCollection[name]
+		data = append(data, []string{
+			name,
+			fmt.Sprint(archived[name]),
+			fmt.Sprint(current[name]),
+			fmt.Sprint(c.missing),
+			fmt.Sprint(c.extra),
+			fmt.Sprint(c.changed),
+		})
+	}
+
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+func describe(a backup.RestoreAction) string {
+	switch {
+	case a.Before == nil:
+		return "create"
+	case a.Merge:
+		return fmt.Sprintf("add %d missing field(s) to", len(a.Data))
+	default:
+		return "overwrite"
+	}
+}
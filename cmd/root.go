@@ -22,21 +22,46 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	sub1 "github.com/donovanmods/projectdaedalus-db-tool/cmd/add"
+	sub8 "github.com/donovanmods/projectdaedalus-db-tool/cmd/audit"
+	sub9 "github.com/donovanmods/projectdaedalus-db-tool/cmd/backup"
+	sub15 "github.com/donovanmods/projectdaedalus-db-tool/cmd/check"
+	sub13 "github.com/donovanmods/projectdaedalus-db-tool/cmd/config"
 	sub2 "github.com/donovanmods/projectdaedalus-db-tool/cmd/del"
+	sub11 "github.com/donovanmods/projectdaedalus-db-tool/cmd/diff"
+	sub14 "github.com/donovanmods/projectdaedalus-db-tool/cmd/discover"
+	sub5 "github.com/donovanmods/projectdaedalus-db-tool/cmd/edit"
 	sub3 "github.com/donovanmods/projectdaedalus-db-tool/cmd/list"
+	sub12 "github.com/donovanmods/projectdaedalus-db-tool/cmd/migrate"
+	sub7 "github.com/donovanmods/projectdaedalus-db-tool/cmd/moderate"
+	sub10 "github.com/donovanmods/projectdaedalus-db-tool/cmd/restore"
+	sub6 "github.com/donovanmods/projectdaedalus-db-tool/cmd/set"
 	sub4 "github.com/donovanmods/projectdaedalus-db-tool/cmd/sync"
+	sub16 "github.com/donovanmods/projectdaedalus-db-tool/cmd/verify"
 
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
 	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/trace"
 )
 
-var cfgFile string
+var (
+	cfgFile string
+	profile string
+)
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
@@ -44,13 +69,35 @@ var RootCmd = &cobra.Command{
 	Version: "0.1.0",
 	Short:   "ProjectDaedalus Database Tool - a CLI utility to manage the Icarus ProjectDaedalus database",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		initConfig(cmd)
+
 		noColor, _ := cmd.Flags().GetBool("no-color")
 		verbosity, _ := cmd.Flags().GetCount("verbose")
 
 		viper.Set("color", !noColor)
 		viper.Set("verbosity", verbosity)
 
-		logger.SetLogger(verbosity)
+		_, err := logger.SetLogger(verbosity, cmd.CommandPath())
+		logger.CheckErr(err)
+
+		if profile := config.Profile(); profile != "" {
+			logger.Log.Info(fmt.Sprintf("Using profile %q (protected: %t)", profile, config.Protected()))
+		}
+
+		metrics.Start(cmd.CommandPath())
+		if addr := viper.GetString("metrics.addr"); addr != "" {
+			metrics.Serve(addr)
+		}
+		logger.AtExit(metrics.Finish)
+
+		logger.CheckErr(tracing.Setup(cmd.Context(), viper.GetString("trace"), cmd.Root().Version))
+		ctx, span := tracing.Start(cmd.Context(), cmd.CommandPath())
+		cmd.SetContext(ctx)
+		logger.AtExit(func(err error) { finishTrace(span, err) })
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		metrics.Finish(nil)
+		finishTrace(trace.SpanFromContext(cmd.Context()), nil)
 	},
 }
 
@@ -58,50 +105,96 @@ var RootCmd = &cobra.Command{
 // This is called by main.main(). It only needs to happen once to the RootCmd.
 func Execute() {
 	RootCmd.SetVersionTemplate(version())
-	err := RootCmd.Execute()
+
+	// Errors can quote credentials, so everything that bypasses slog is redacted as well
+	RootCmd.SetErr(logger.NewRedactingWriter(os.Stderr))
+	log.SetOutput(logger.NewRedactingWriter(os.Stderr))
+
+	// Interrupts cancel the command's context so long-running commands can stop cleanly.
+	// A second interrupt is not caught and terminates pdt immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := RootCmd.ExecuteContext(ctx)
+	stop()
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-
-	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.pdtconfig.yaml)")
+	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is to merge /etc/pdt/config.*, $XDG_CONFIG_HOME/pdt/config.*, $HOME/.pdtconfig.* and ./.pdtconfig.*)")
+	RootCmd.PersistentFlags().StringVar(&profile, "profile", "", "config profile to use (default is $"+config.ProfileEnv+")")
 	RootCmd.PersistentFlags().CountP("verbose", "v", "verbose output (may be repeated)")
 	RootCmd.PersistentFlags().Bool("dryrun", false, "run without performing any persistent operations")
 	RootCmd.PersistentFlags().Bool("color", true, "colorize output")
 	RootCmd.PersistentFlags().Bool("no-color", false, "disable color output")
 
+	RootCmd.PersistentFlags().String("log-format", logger.FormatPterm, "log format (pterm, text or json)")
+	RootCmd.PersistentFlags().String("log-level", "", "log levels, globally or per subsystem (e.g. firestore=debug,sync=warn)")
+	RootCmd.PersistentFlags().String("metrics-file", "", "write Prometheus metrics to this file when the command finishes (for the textfile collector)")
+	RootCmd.PersistentFlags().String("metrics-addr", "", "serve Prometheus metrics on /metrics at this address (e.g. :9090)")
+	RootCmd.PersistentFlags().String("trace", "", "export traces to stdout, file:<path> or otlp (configured by the OTEL_EXPORTER_OTLP_* variables)")
+	RootCmd.PersistentFlags().String("log-file", "", "write logs to this file, rotating it by size")
+
 	_ = viper.BindPFlag("dryrun", RootCmd.PersistentFlags().Lookup("dryrun"))
+	_ = viper.BindPFlag("log.format", RootCmd.PersistentFlags().Lookup("log-format"))
+	_ = viper.BindPFlag("log.level", RootCmd.PersistentFlags().Lookup("log-level"))
+	_ = viper.BindPFlag("log.file", RootCmd.PersistentFlags().Lookup("log-file"))
+	_ = viper.BindPFlag("metrics.file", RootCmd.PersistentFlags().Lookup("metrics-file"))
+	_ = viper.BindPFlag("metrics.addr", RootCmd.PersistentFlags().Lookup("metrics-addr"))
+	_ = viper.BindPFlag("trace", RootCmd.PersistentFlags().Lookup("trace"))
 
 	RootCmd.AddCommand(sub1.AddCmd)
 	RootCmd.AddCommand(sub2.DelCmd)
 	RootCmd.AddCommand(sub3.ListCmd)
 	RootCmd.AddCommand(sub4.SyncCmd)
+	RootCmd.AddCommand(sub5.EditCmd)
+	RootCmd.AddCommand(sub6.SetCmd)
+	RootCmd.AddCommand(sub6.UnsetCmd)
+	RootCmd.AddCommand(sub7.ModerateCmd)
+	RootCmd.AddCommand(sub8.AuditCmd)
+	RootCmd.AddCommand(sub9.BackupCmd)
+	RootCmd.AddCommand(sub10.RestoreCmd)
+	RootCmd.AddCommand(sub11.DiffCmd)
+	RootCmd.AddCommand(sub12.MigrateCmd)
+	RootCmd.AddCommand(sub13.ConfigCmd)
+	RootCmd.AddCommand(sub14.DiscoverCmd)
+	RootCmd.AddCommand(sub15.CheckCmd)
+	RootCmd.AddCommand(sub16.VerifyCmd)
 }
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".pdtconfig" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigType("json")
-		viper.SetConfigName(".pdtconfig")
+// initConfig reads in config files and ENV variables if set.
+// Commands annotated with config.AnnotationNoConfig may run even when the file named by --config does not exist.
+func initConfig(cmd *cobra.Command) {
+	// read in environment variables that match, e.g. PDT_GITHUB_TOKEN for github.token
+	viper.SetEnvPrefix(config.EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	if err := config.Load(cfgFile); err != nil {
+		if config.NoConfig(cmd) && errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		log.Fatal("Unable to read config file: ", err)
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	if profile == "" {
+		profile = os.Getenv(config.ProfileEnv)
+	}
+	if err := config.SelectProfile(profile); err != nil {
+		log.Fatal(err)
+	}
+}
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatal("Unable to read config file, this is a required file: ", viper.ConfigFileUsed())
+// finishTrace ends the command span and flushes every buffered span
+func finishTrace(span trace.Span, err error) {
+	tracing.End(span, err)
+	if err := tracing.Shutdown(); err != nil {
+		logger.Log.Error(fmt.Sprintf("Unable to export traces: %v", err))
 	}
 }
 
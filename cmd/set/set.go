@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package setCmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// SetCmd represents the set command
+var SetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set fields on a mod or tool document",
+	Long: `Sets one or more fields on a mod or tool document.
+
+Values are converted to the type of the field (bool, integer, timestamp or comma-separated list)
+and the resulting document is validated before it is written. Nested fields are addressed with
+dotted paths. For example:
+
+  pdt set mod <id> version=1.2.0 files.pak=https://example.com/mod.pak
+
+With --dryrun the resulting document is printed instead of written.`,
+}
+
+func init() {
+	for _, kind := range models.Kinds {
+		SetCmd.AddCommand(&cobra.Command{
+			Use:   kind + " <id> <key=value>...",
+			Short: fmt.Sprintf("Set fields on a %s document", kind),
+			Args:  cobra.MinimumNArgs(2),
+			Run: func(cmd *cobra.Command, args []string) {
+				logger.CheckErr(apply(cmd.Context(), kind, args[0], func(doc models.Document) error {
+					for _, arg := range args[1:] {
+						key, value, ok := strings.Cut(arg, "=")
+						if !ok {
+							return fmt.Errorf("invalid assignment %q (expected key=value)", arg)
+						}
+						if err := models.SetField(doc, key, value); err != nil {
+							return err
+						}
+					}
+					return nil
+				}))
+			},
+		})
+	}
+}
+
+// apply fetches a document, applies change to it, validates the result and writes it back
+func apply(ctx context.Context, kind, id string, change func(models.Document) error) error {
+	before, updated, err := firestore.GetEntry(ctx, kind, id)
+	if err != nil {
+		return err
+	}
+
+	after, err := models.Clone(before)
+	if err != nil {
+		return err
+	}
+
+	if err := change(after); err != nil {
+		return err
+	}
+	if err := after.Validate(); err != nil {
+		return fmt.Errorf("invalid %s:\n%w", kind, err)
+	}
+
+	if viper.GetBool("dryrun") {
+		out, err := yaml.Marshal(after)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+		pterm.Info.Println("Dry run: changes were not written")
+		return nil
+	}
+
+	if err := firestore.UpdateEntry(ctx, kind, id, before, after, updated); err != nil {
+		return err
+	}
+
+	pterm.Success.Printfln("Updated %s %q", kind, id)
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2025 Donovan C. Young <dyoung522@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package setCmd
+
+import (
+	"fmt"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/spf13/cobra"
+)
+
+// UnsetCmd represents the unset command
+var UnsetCmd = &cobra.Command{
+	Use:   "unset",
+	Short: "Remove fields from a mod or tool document",
+	Long: `Removes one or more fields from a mod or tool document, resetting them to their empty value.
+
+Nested fields are addressed with dotted paths (e.g. files.zip). The resulting document is validated
+before it is written, and --dryrun prints it instead of writing it.`,
+}
+
+func init() {
+	for _, kind := range models.Kinds {
+		UnsetCmd.AddCommand(&cobra.Command{
+			Use:   kind + " <id> <key>...",
+			Short: fmt.Sprintf("Remove fields from a %s document", kind),
+			Args:  cobra.MinimumNArgs(2),
+			Run: func(cmd *cobra.Command, args []string) {
+				logger.CheckErr(apply(cmd.Context(), kind, args[0], func(doc models.Document) error {
+					for _, key := range args[1:] {
+						if err := models.UnsetField(doc, key); err != nil {
+							return err
+						}
+					}
+					return nil
+				}))
+			},
+		})
+	}
+}
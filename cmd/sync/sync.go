@@ -22,34 +22,104 @@ THE SOFTWARE.
 package syncCmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/syncer"
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
-// syncCmd represents the sync command
+// SyncCmd represents the sync command
 var SyncCmd = &cobra.Command{
 	Use:   "sync",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Short: "Synchronize mods and tools from their modinfo and toolinfo sources",
+	Long: `Looks for modinfo.json and toolinfo.json files in every configured repository, then
+rewrites each mod and tool document from its source.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+Moderation fields (hidden, featured, blocked) are kept when a document is rewritten,
+and blocked entries are never re-created.
+
+With --interval the sync repeats until interrupted, which pairs well with --metrics-addr.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("sync called")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if interval <= 0 {
+			logger.CheckErr(run(cmd.Context()))
+			return
+		}
+
+		ctx := cmd.Context()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			metrics.Start(cmd.CommandPath())
+			err := run(ctx)
+			if err != nil {
+				logger.Log.Error(err.Error())
+			}
+			metrics.Finish(err)
+
+			select {
+			case <-ctx.Done():
+				logger.Log.Info("Interrupted, stopping the sync loop")
+				return
+			case <-ticker.C:
+			}
+		}
 	},
 }
 
 func init() {
-	// Here you will define your flags and configuration settings.
+	SyncCmd.Flags().Duration("interval", 0, "repeat the sync at this interval until interrupted (e.g. 1h)")
+}
+
+// run performs one sync and prints its results
+func run(ctx context.Context) error {
+	results, problems, err := syncer.Run(ctx)
+	printResults(results)
+	for _, h := range problems {
+		pterm.Warning.Println(h)
+	}
+	if len(problems) > 0 {
+		pterm.Info.Println(`Run "pdt check repos --fix" to update moved repositories and mark the others inactive`)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if len(r.Errors) > 0 {
+			return errors.New("sync completed with errors")
+		}
+	}
+
+	return nil
+}
+
+func printResults(results []syncer.Result) {
+	if len(results) == 0 {
+		return
+	}
 
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// syncCmd.PersistentFlags().String("foo", "", "A help for foo")
+	data := pterm.TableData{{"Kind", "Created", "Updated", "Unchanged", "Blocked", "Invalid", "Errors"}}
+	for _, r := range results {
+		data = append(data, []string{
+			r.Kind,
+			fmt.Sprint(r.Created),
+			fmt.Sprint(r.Updated),
+			fmt.Sprint(r.Unchanged),
+			fmt.Sprint(r.Blocked),
+			fmt.Sprint(r.Invalid),
+			fmt.Sprint(len(r.Errors)),
+		})
+		for _, err := range r.Errors {
+			pterm.Error.Println(err)
+		}
+	}
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// syncCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
 }
@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 Donovan C. Young
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package verifyCmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/verify"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// filesCmd represents the verify files command
+var filesCmd = &cobra.Command{
+	Use:   "files [id...]",
+	Short: "Download every mod file and record its SHA-256 digest",
+	Long: `Downloads the files of every mod (or only the mods given by ID), computes their SHA-256
+digests and records them on the mod documents in file_info, along with the time and the mod
+version they were verified at.
+
+Files whose content changed since the last verification while the mod kept the same version
+are flagged. Downloads are streamed into a content-addressed cache and abandoned when they
+grow beyond --max-size.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		maxSize, _ := cmd.Flags().GetInt64("max-size")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		dir, _ := cmd.Flags().GetString("cache-dir")
+
+		if dir == "" {
+			var err error
+			dir, err = verify.DefaultStoreDir()
+			logger.CheckErr(err)
+		}
+		store, err := verify.OpenStore(dir)
+		logger.CheckErr(err)
+
+		files, err := verify.Files(cmd.Context(), store, verify.Options{MaxSize: maxSize, Concurrency: concurrency, Timeout: timeout, IDs: args})
+		if len(files) > 0 {
+			printFiles(files)
+		}
+		logger.CheckErr(err)
+
+		failed, changed := 0, 0
+		for _, f := range files {
+			if f.Err != nil {
+				failed++
+			} else if f.ChangedWithoutBump {
+				changed++
+			}
+		}
+		if viper.GetBool("dryrun") {
+			pterm.Info.Println("Dry run: digests were not written")
+		}
+		pterm.Info.Printfln("Verified %d of %d files: %d changed without a version bump, %d failed", len(files)-failed, len(files), changed, failed)
+
+		if failed > 0 || changed > 0 {
+			logger.CheckErr(fmt.Errorf("%d file(s) failed or changed without a version bump", failed+changed))
+		}
+	},
+}
+
+func init() {
+	filesCmd.Flags().Int64("max-size", 512<<20, "largest file to download, in bytes")
+	filesCmd.Flags().Int("concurrency", 4, "number of files downloaded at once")
+	filesCmd.Flags().Duration("timeout", 10*time.Minute, "timeout for each download")
+	filesCmd.Flags().String("cache-dir", "", "directory of the content-addressed download cache (default: the user cache directory)")
+	VerifyCmd.AddCommand(filesCmd)
+}
+
+// printFiles shows a table of the verified files
+func printFiles(files []verify.File) {
+	data := pterm.TableData{{"Mod", "File", "Size", "SHA-256", "Result"}}
+	for _, f := range files {
+		result, digest := "ok", f.SHA256
+		switch {
+		case f.Err != nil:
+			result = f.Err.Error()
+		case f.ChangedWithoutBump:
+			result = "changed without a version bump (" + f.Version + ")"
+		}
+		if len(digest) > 12 {
+			digest = digest[:12]
+		}
+		data = append(data, []string{fmt.Sprintf("%s/%s (%s)", f.Author, f.Name, f.ID), f.FileType, fmt.Sprint(f.Size), digest, result})
+	}
+
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
@@ -0,0 +1,235 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+)
+
+const (
+	manifestName = "manifest.json"
+	documentsDir = "documents/"
+)
+
+// Manifest describes the contents of a backup archive
+type Manifest struct {
+	// Format is the version of the document encoding; archives in any other format are rejected
+	Format      int               `json:"format"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ToolVersion string            `json:"tool_version"`
+	ProjectID   string            `json:"project_id"`
+	Counts      map[string]int    `json:"counts"`
+	Checksums   map[string]string `json:"checksums"`
+}
+
+// Filename returns the default, timestamped archive name for a project
+func Filename(projectID string, at time.Time) string {
+	if projectID == "" {
+		projectID = "pdt"
+	}
+
+	return fmt.Sprintf("%s-backup-%s.tar.gz", projectID, at.UTC().Format("20060102T150405Z"))
+}
+
+// WriteArchive writes the snapshot to a tar.gz archive of JSON documents along with its manifest
+func WriteArchive(file string, snap *Snapshot, toolVersion, projectID string) (*Manifest, error) {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(subsystem).Info(fmt.Sprintf("Writing %d documents to %s", len(snap.Documents), file))
+	manifest, err := writeArchive(f, snap, toolVersion, projectID)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(file)
+		metrics.Error(subsystem, err)
+		return nil, err
+	}
+
+	metrics.Set(metrics.BackupDocuments, float64(len(snap.Documents)))
+	if info, err := os.Stat(file); err == nil {
+		metrics.Set(metrics.BackupSize, float64(info.Size()))
+	}
+
+	return manifest, nil
+}
+
+func writeArchive(w io.Writer, snap *Snapshot, toolVersion, projectID string) (*Manifest, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest := &Manifest{
+		Format:      archiveFormat,
+		CreatedAt:   time.Now().UTC(),
+		ToolVersion: toolVersion,
+		ProjectID:   projectID,
+		Counts:      snap.Counts(),
+		Checksums:   make(map[string]string, len(snap.Documents)),
+	}
+
+	for _, docPath := range snap.Paths() {
+		doc, err := encodeDocument(snap.Documents[docPath])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", docPath, err)
+		}
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", docPath, err)
+		}
+
+		name := documentsDir + docPath + ".json"
+		if err := writeFile(tw, name, data, manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+		manifest.Checksums[name] = checksum(data)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(tw, manifestName, data, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, gz.Close()
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	_, err := tw.Write(data)
+	return err
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadArchive reads a backup archive and verifies every document against the manifest checksums
+func ReadArchive(file string) (*Snapshot, *Manifest, error) {
+	logger.For(subsystem).Info(fmt.Sprintf("Reading backup archive %s", file))
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gz.Close()
+
+	var manifest *Manifest
+	files := make(map[string][]byte)
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if hdr.Name == manifestName {
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", manifestName, err)
+			}
+			continue
+		}
+		files[path.Clean(hdr.Name)] = data
+	}
+
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("%s is not a backup archive (no %s)", file, manifestName)
+	}
+	if manifest.Format != archiveFormat {
+		return nil, nil, fmt.Errorf("%s has unsupported format %d (expected %d)", file, manifest.Format, archiveFormat)
+	}
+
+	snap, err := verify(manifest, files)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return snap, manifest, nil
+}
+
+// verify checks the archived files against the manifest and decodes them into a snapshot
+func verify(manifest *Manifest, files map[string][]byte) (*Snapshot, error) {
+	var errs []error
+
+	for name := range files {
+		if _, ok := manifest.Checksums[name]; !ok {
+			errs = append(errs, fmt.Errorf("%s is not listed in the manifest", name))
+		}
+	}
+
+	snap := NewSnapshot()
+	for name, sum := range manifest.Checksums {
+		data, ok := files[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s is missing", name))
+			continue
+		}
+		if checksum(data) != sum {
+			errs = append(errs, fmt.Errorf("%s does not match its checksum", name))
+			continue
+		}
+
+		doc, err := decodeDocument(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		snap.Documents[strings.TrimSuffix(strings.TrimPrefix(name, documentsDir), ".json")] = doc
+	}
+
+	counts := snap.Counts()
+	for collection, count := range manifest.Counts {
+		if got := counts[collection]; got != count && len(errs) == 0 {
+			errs = append(errs, fmt.Errorf("%s has %d documents, manifest lists %d", collection, got, count))
+		}
+	}
+
+	return snap, errors.Join(errs...)
+}
@@ -0,0 +1,46 @@
+package backup
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/diff"
+)
+
+// Change statuses reported by Compare
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// DocumentChange describes how a single document differs between two snapshots
+type DocumentChange struct {
+	Path    string             `json:"path"`
+	Status  string             `json:"status"`
+	Changes []diff.FieldChange `json:"changes,omitempty"`
+}
+
+// Compare reports the documents that were added, removed or changed going from a to b, sorted by path
+func Compare(a, b *Snapshot) []DocumentChange {
+	var changes []DocumentChange
+
+	paths := slices.Concat(a.Paths(), b.Paths())
+	slices.Sort(paths)
+
+	for _, p := range slices.Compact(paths) {
+		before, inA := a.Documents[p]
+		after, inB := b.Documents[p]
+
+		switch {
+		case !inA:
+			changes = append(changes, DocumentChange{Path: p, Status: Added, Changes: diff.Fields(nil, after)})
+		case !inB:
+			changes = append(changes, DocumentChange{Path: p, Status: Removed, Changes: diff.Fields(before, nil)})
+		case !reflect.DeepEqual(before, after):
+			changes = append(changes, DocumentChange{Path: p, Status: Changed, Changes: diff.Fields(before, after)})
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+)
+
+// Conflict policies for documents that already exist in the live database
+const (
+	// Overwrite replaces live documents that differ from the archive
+	Overwrite = "overwrite"
+	// SkipExisting leaves every live document untouched and only creates missing documents
+	SkipExisting = "skip-existing"
+	// OnlyMissing creates missing documents and adds missing fields to existing ones, never changing a live value
+	OnlyMissing = "only-missing"
+)
+
+// Policies lists every supported conflict policy
+var Policies = []string{Overwrite, SkipExisting, OnlyMissing}
+
+// RestoreAction is a single document write planned by PlanRestore
+type RestoreAction struct {
+	Path   string
+	Before map[string]any
+	Data   map[string]any
+	Merge  bool
+}
+
+// PlanRestore works out the writes needed to restore archive over live using the given conflict policy
+func PlanRestore(archive, live *Snapshot, policy string) ([]RestoreAction, error) {
+	switch policy {
+	case Overwrite, SkipExisting, OnlyMissing:
+	default:
+		return nil, fmt.Errorf("unknown conflict policy %q (expected one of: %s)", policy, strings.Join(Policies, ", "))
+	}
+
+	var actions []RestoreAction
+	for _, p := range archive.Paths() {
+		data := archive.Documents[p]
+
+		current, exists := live.Documents[p]
+		if !exists {
+			actions = append(actions, RestoreAction{Path: p, Data: data})
+			continue
+		}
+		if reflect.DeepEqual(current, data) {
+			continue
+		}
+
+		switch policy {
+		case Overwrite:
+			actions = append(actions, RestoreAction{Path: p, Before: current, Data: data})
+		case OnlyMissing:
+			missing := make(map[string]any)
+			for k, v := range data {
+				if _, ok := current[k]; !ok {
+					missing[k] = v
+				}
+			}
+			if len(missing) > 0 {
+				actions = append(actions, RestoreAction{Path: p, Before: current, Data: missing, Merge: true})
+			}
+		}
+	}
+
+	return actions, nil
+}
+
+// restoreBatchSize is the number of documents written per transaction.
+// Every write is committed with its audit entry, which keeps a batch well under Firestore's 500 writes per commit.
+const restoreBatchSize = 200
+
+// Restore performs the planned writes in batches of one transaction each and returns the number of actions applied.
+// A failed batch is rolled back as a whole and nothing after it is attempted, so on error exactly the first
+// applied actions are in the database.
+func Restore(ctx context.Context, actions []RestoreAction) (int, error) {
+	return restoreBatches(actions, func(batch []RestoreAction) error {
+		_, err := firestore.RunTransaction(ctx, false, func(tx *firestore.Tx) error {
+			for _, a := range batch {
+				logger.For(subsystem).Debug(fmt.Sprintf("Restoring %q (merge: %t)", a.Path, a.Merge))
+
+				var err error
+				if a.Merge {
+					err = tx.Merge(a.Path, a.Data)
+				} else {
+					err = tx.Set(a.Path, a.Data)
+				}
+				if err != nil {
+					return fmt.Errorf("%s: %w", a.Path, err)
+				}
+			}
+			return nil
+		})
+		return err
+	})
+}
+
+// restoreBatches passes the actions to apply in batches of restoreBatchSize, stopping at the first failed batch
+func restoreBatches(actions []RestoreAction, apply func(batch []RestoreAction) error) (int, error) {
+	applied := 0
+	for batch := range slices.Chunk(actions, restoreBatchSize) {
+		if err := apply(batch); err != nil {
+			return applied, err
+		}
+		applied += len(batch)
+	}
+
+	return applied, nil
+}
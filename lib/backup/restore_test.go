@@ -0,0 +1,123 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func restoreSnapshots() (archive, live *Snapshot) {
+	archive, live = NewSnapshot(), NewSnapshot()
+
+	// Only in the archive
+	archive.Documents["mods/new"] = map[string]any{"name": "New"}
+	// Identical on both sides
+	archive.Documents["mods/same"] = map[string]any{"name": "Same"}
+	live.Documents["mods/same"] = map[string]any{"name": "Same"}
+	// Changed and missing fields
+	archive.Documents["mods/changed"] = map[string]any{"name": "Old name", "author": "Jane"}
+	live.Documents["mods/changed"] = map[string]any{"name": "New name"}
+	// Changed fields only
+	archive.Documents["mods/edited"] = map[string]any{"name": "Old name"}
+	live.Documents["mods/edited"] = map[string]any{"name": "New name"}
+	// Only in the live database
+	live.Documents["mods/live"] = map[string]any{"name": "Live"}
+
+	return archive, live
+}
+
+func TestPlanRestore(t *testing.T) {
+	newDoc := RestoreAction{Path: "mods/new", Data: map[string]any{"name": "New"}}
+
+	tests := []struct {
+		policy string
+		want   []RestoreAction
+	}{
+		{Overwrite, []RestoreAction{
+			{
+				Path:   "mods/changed",
+				Before: map[string]any{"name": "New name"},
+				Data:   map[string]any{"name": "Old name", "author": "Jane"},
+			},
+			{
+				Path:   "mods/edited",
+				Before: map[string]any{"name": "New name"},
+				Data:   map[string]any{"name": "Old name"},
+			},
+			newDoc,
+		}},
+		{SkipExisting, []RestoreAction{newDoc}},
+		{OnlyMissing, []RestoreAction{
+			{
+				Path:   "mods/changed",
+				Before: map[string]any{"name": "New name"},
+				Data:   map[string]any{"author": "Jane"},
+				Merge:  true,
+			},
+			newDoc,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			archive, live := restoreSnapshots()
+
+			got, err := PlanRestore(archive, live, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanRestore() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanRestoreRejectsUnknownPolicy(t *testing.T) {
+	archive, live := restoreSnapshots()
+
+	if _, err := PlanRestore(archive, live, "fail"); err == nil {
+		t.Error("PlanRestore() accepted an unknown policy")
+	}
+}
+
+func TestRestoreBatches(t *testing.T) {
+	actions := make([]RestoreAction, 2*restoreBatchSize+50)
+	for i := range actions {
+		actions[i] = RestoreAction{Path: fmt.Sprintf("mods/%d", i)}
+	}
+
+	var sizes []int
+	applied, err := restoreBatches(actions, func(batch []RestoreAction) error {
+		sizes = append(sizes, len(batch))
+		return nil
+	})
+	if err != nil || applied != len(actions) {
+		t.Fatalf("restoreBatches() = %d, %v; want %d, nil", applied, err, len(actions))
+	}
+	if want := []int{restoreBatchSize, restoreBatchSize, 50}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("batch sizes = %v, want %v", sizes, want)
+	}
+}
+
+func TestRestoreBatchesStopsAtFailedBatch(t *testing.T) {
+	actions := make([]RestoreAction, 3*restoreBatchSize)
+	failure := errors.New("commit failed")
+
+	calls := 0
+	applied, err := restoreBatches(actions, func(batch []RestoreAction) error {
+		calls++
+		if calls == 2 {
+			return failure
+		}
+		return nil
+	})
+
+	if !errors.Is(err, failure) {
+		t.Errorf("restoreBatches() error = %v, want %v", err, failure)
+	}
+	if applied != restoreBatchSize || calls != 2 {
+		t.Errorf("applied %d actions in %d calls, want %d in 2", applied, calls, restoreBatchSize)
+	}
+}
@@ -0,0 +1,132 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"time"
+
+	gfs "cloud.google.com/go/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"google.golang.org/genproto/googleapis/type/latlng"
+)
+
+const subsystem = "backup"
+
+// Snapshot is a point-in-time copy of database documents keyed by document path (e.g. "mods/abc123")
+type Snapshot struct {
+	Documents map[string]map[string]any
+}
+
+// NewSnapshot returns an empty snapshot
+func NewSnapshot() *Snapshot {
+	return &Snapshot{Documents: make(map[string]map[string]any)}
+}
+
+// Live reads every configured collection and meta document from Firestore
+func Live(ctx context.Context) (*Snapshot, error) {
+	return LivePaths(ctx, firestore.ConfiguredPaths())
+}
+
+// LivePaths reads the given collections and documents from Firestore
+func LivePaths(ctx context.Context, paths []string) (*Snapshot, error) {
+	snap := NewSnapshot()
+
+	for _, p := range paths {
+		logger.For(subsystem).Debug(fmt.Sprintf("Reading %q", p))
+		docs, err := firestore.ReadDocuments(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		for docPath, data := range docs {
+			snap.Documents[docPath] = normalize(data).(map[string]any)
+		}
+	}
+
+	return snap, nil
+}
+
+// Filter returns a snapshot holding only the documents in the given collections
+func (s *Snapshot) Filter(collections []string) *Snapshot {
+	if len(collections) == 0 {
+		return s
+	}
+
+	filtered := NewSnapshot()
+	for p, doc := range s.Documents {
+		if slices.Contains(collections, Collection(p)) {
+			filtered.Documents[p] = doc
+		}
+	}
+
+	return filtered
+}
+
+// Collections returns the collections present in the snapshot, sorted
+func (s *Snapshot) Collections() []string {
+	return slices.Sorted(maps.Keys(s.Counts()))
+}
+
+// Paths returns the document paths in the snapshot, sorted
+func (s *Snapshot) Paths() []string {
+	paths := make([]string, 0, len(s.Documents))
+	for p := range s.Documents {
+		paths = append(paths, p)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// Counts returns the number of documents in the snapshot grouped by collection
+func (s *Snapshot) Counts() map[string]int {
+	counts := make(map[string]int)
+	for p := range s.Documents {
+		counts[Collection(p)]++
+	}
+
+	return counts
+}
+
+// Collection returns the collection a document path belongs to
+func Collection(docPath string) string {
+	return path.Dir(docPath)
+}
+
+// normalize converts values read from Firestore into the same shape they have after an archive round trip,
+// so live documents and archived documents compare equal
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = normalize(e)
+		}
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, e := range v {
+			l[i] = normalize(e)
+		}
+		return l
+	case time.Time:
+		return v.UTC()
+	case *gfs.DocumentRef:
+		if v == nil {
+			return nil
+		}
+		return firestore.ReferenceOf(v)
+	case *latlng.LatLng:
+		if v == nil {
+			return nil
+		}
+		// A fresh message carries no protobuf internal state, so it compares equal to a decoded one
+		return &latlng.LatLng{Latitude: v.Latitude, Longitude: v.Longitude}
+	default:
+		return v
+	}
+}
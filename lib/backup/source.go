@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LiveSource is the source name that refers to the live database
+const LiveSource = "live"
+
+// Open loads a snapshot from a backup archive, a local directory of JSON documents or, for "live", the live database
+func Open(ctx context.Context, source string) (*Snapshot, error) {
+	if source == LiveSource {
+		return Live(ctx)
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return ReadDir(source)
+	}
+
+	snap, _, err := ReadArchive(source)
+	return snap, err
+}
+
+// ReadDir reads a directory of JSON documents laid out as <collection>/<id>.json, such as an extracted backup archive
+func ReadDir(dir string) (*Snapshot, error) {
+	root := dir
+	if info, err := os.Stat(filepath.Join(dir, documentsDir)); err == nil && info.IsDir() {
+		root = filepath.Join(dir, documentsDir)
+	}
+
+	snap := NewSnapshot()
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		docPath := strings.TrimSuffix(filepath.ToSlash(rel), ".json")
+		if !strings.Contains(docPath, "/") {
+			// Top-level files (e.g. manifest.json) are not documents
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		doc, err := decodeDocument(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		snap.Documents[docPath] = doc
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snap, nil
+}
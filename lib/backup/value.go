@@ -0,0 +1,212 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"google.golang.org/genproto/googleapis/type/latlng"
+)
+
+// archiveFormat is the manifest format of archives whose documents tag every value with its Firestore type
+const archiveFormat = 1
+
+// Value type tags, named after the Firestore REST API value fields
+const (
+	tagNull      = "nullValue"
+	tagBoolean   = "booleanValue"
+	tagInteger   = "integerValue"
+	tagDouble    = "doubleValue"
+	tagString    = "stringValue"
+	tagTimestamp = "timestampValue"
+	tagBytes     = "bytesValue"
+	tagReference = "referenceValue"
+	tagGeoPoint  = "geoPointValue"
+	tagArray     = "arrayValue"
+	tagMap       = "mapValue"
+)
+
+// encodeDocument wraps every value of a document with its type, e.g. {"version": {"stringValue": "1.0"}},
+// so decoding returns exactly the types that were read from Firestore
+func encodeDocument(doc map[string]any) (map[string]any, error) {
+	fields := make(map[string]any, len(doc))
+	for k, v := range doc {
+		e, err := encodeValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		fields[k] = e
+	}
+
+	return map[string]any{"fields": fields}, nil
+}
+
+func encodeValue(v any) (map[string]any, error) {
+	switch v := v.(type) {
+	case nil:
+		return map[string]any{tagNull: nil}, nil
+	case bool:
+		return map[string]any{tagBoolean: v}, nil
+	case int64:
+		// Integers are stored as strings so they survive JSON decoders that only know float64
+		return map[string]any{tagInteger: strconv.FormatInt(v, 10)}, nil
+	case int:
+		return map[string]any{tagInteger: strconv.Itoa(v)}, nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return map[string]any{tagDouble: strconv.FormatFloat(v, 'g', -1, 64)}, nil
+		}
+		return map[string]any{tagDouble: v}, nil
+	case string:
+		return map[string]any{tagString: v}, nil
+	case time.Time:
+		return map[string]any{tagTimestamp: v.UTC().Format(time.RFC3339Nano)}, nil
+	case []byte:
+		return map[string]any{tagBytes: base64.StdEncoding.EncodeToString(v)}, nil
+	case firestore.Reference:
+		return map[string]any{tagReference: string(v)}, nil
+	case *latlng.LatLng:
+		if v == nil {
+			return map[string]any{tagNull: nil}, nil
+		}
+		return map[string]any{tagGeoPoint: map[string]any{"latitude": v.Latitude, "longitude": v.Longitude}}, nil
+	case []any:
+		values := make([]any, len(v))
+		for i, e := range v {
+			ev, err := encodeValue(e)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+			values[i] = ev
+		}
+		return map[string]any{tagArray: map[string]any{"values": values}}, nil
+	case map[string]any:
+		fields, err := encodeDocument(v)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{tagMap: fields}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+// decodeDocument parses a document written by encodeDocument
+func decodeDocument(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc struct {
+		Fields map[string]any `json:"fields"`
+	}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return decodeFields(doc.Fields)
+}
+
+func decodeFields(fields map[string]any) (map[string]any, error) {
+	doc := make(map[string]any, len(fields))
+	for k, e := range fields {
+		v, err := decodeTypedValue(e)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		doc[k] = v
+	}
+
+	return doc, nil
+}
+
+func decodeTypedValue(e any) (any, error) {
+	tagged, ok := e.(map[string]any)
+	if !ok || len(tagged) != 1 {
+		return nil, fmt.Errorf("value is not tagged with a type")
+	}
+
+	for tag, v := range tagged {
+		switch tag {
+		case tagNull:
+			return nil, nil
+		case tagBoolean:
+			if b, ok := v.(bool); ok {
+				return b, nil
+			}
+		case tagInteger:
+			if s, ok := v.(string); ok {
+				return strconv.ParseInt(s, 10, 64)
+			}
+		case tagDouble:
+			if f, err := number(v); err == nil {
+				return f, nil
+			}
+		case tagString:
+			if s, ok := v.(string); ok {
+				return s, nil
+			}
+		case tagTimestamp:
+			if s, ok := v.(string); ok {
+				t, err := time.Parse(time.RFC3339Nano, s)
+				return t.UTC(), err
+			}
+		case tagBytes:
+			if s, ok := v.(string); ok {
+				return base64.StdEncoding.DecodeString(s)
+			}
+		case tagReference:
+			if s, ok := v.(string); ok && firestore.IsDocumentPath(s) {
+				return firestore.Reference(s), nil
+			}
+		case tagGeoPoint:
+			if m, ok := v.(map[string]any); ok {
+				lat, err1 := number(m["latitude"])
+				lng, err2 := number(m["longitude"])
+				if err1 == nil && err2 == nil {
+					return &latlng.LatLng{Latitude: lat, Longitude: lng}, nil
+				}
+			}
+		case tagArray:
+			if m, ok := v.(map[string]any); ok {
+				items, _ := m["values"].([]any)
+				values := make([]any, len(items))
+				for i, item := range items {
+					value, err := decodeTypedValue(item)
+					if err != nil {
+						return nil, fmt.Errorf("%d: %w", i, err)
+					}
+					values[i] = value
+				}
+				return values, nil
+			}
+		case tagMap:
+			if m, ok := v.(map[string]any); ok {
+				fields, _ := m["fields"].(map[string]any)
+				return decodeFields(fields)
+			}
+		default:
+			return nil, fmt.Errorf("unknown value type %q", tag)
+		}
+
+		return nil, fmt.Errorf("invalid %s %v", tag, v)
+	}
+
+	return nil, nil
+}
+
+// number parses a JSON number, or the string form used for NaN and infinities
+func number(v any) (float64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Float64()
+	case string:
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("%v is not a number", v)
+	}
+}
@@ -0,0 +1,124 @@
+package backup
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	gfs "cloud.google.com/go/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"google.golang.org/genproto/googleapis/type/latlng"
+)
+
+func sampleDocument() map[string]any {
+	return map[string]any{
+		"name":        "Better Stacks",
+		"version":     "2025-01-02T03:04:05Z",
+		"description": "42",
+		"downloads":   int64(42),
+		"rating":      float64(4),
+		"score":       4.5,
+		"hidden":      false,
+		"notes":       nil,
+		"created_at":  time.Date(2025, 1, 2, 3, 4, 5, 600, time.UTC),
+		"icon":        []byte{0x89, 'P', 'N', 'G'},
+		"tags":        []any{"qol", int64(1), 1.5},
+		"files": map[string]any{
+			"pak": "https://example.com/mod.pak",
+			"fileInfo": map[string]any{
+				"size": int64(1024),
+			},
+		},
+		"empty":    map[string]any{},
+		"author":   firestore.Reference("authors/jane"),
+		"location": &latlng.LatLng{Latitude: 52.52, Longitude: -13.4},
+	}
+}
+
+func TestTypedDocumentRoundTrip(t *testing.T) {
+	doc := sampleDocument()
+
+	encoded, err := encodeDocument(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := decodeDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("round trip changed the document\n got: %#v\nwant: %#v", decoded, doc)
+	}
+}
+
+func TestTypedDocumentRejectsUntaggedValues(t *testing.T) {
+	if _, err := decodeDocument([]byte(`{"fields": {"name": "Better Stacks"}}`)); err == nil {
+		t.Error("decoded an untagged value, want an error")
+	}
+	if _, err := decodeDocument([]byte(`{"fields": {"name": {"fooValue": 1}}}`)); err == nil {
+		t.Error("decoded an unknown type tag, want an error")
+	}
+	if _, err := decodeDocument([]byte(`{"fields": {"n": {"integerValue": "1.5"}}}`)); err == nil {
+		t.Error("decoded a fractional integerValue, want an error")
+	}
+	if _, err := decodeDocument([]byte(`{"fields": {"r": {"referenceValue": "authors"}}}`)); err == nil {
+		t.Error("decoded a reference to a collection, want an error")
+	}
+}
+
+func TestEncodeDocumentRejectsUnsupportedTypes(t *testing.T) {
+	if _, err := encodeDocument(map[string]any{"c": make(chan int)}); err == nil {
+		t.Error("encoded a channel, want an error")
+	}
+}
+
+func TestNormalizeMatchesDecodedValues(t *testing.T) {
+	raw := map[string]any{
+		"author":   &gfs.DocumentRef{ID: "jane", Path: "projects/p/databases/(default)/documents/authors/jane"},
+		"location": &latlng.LatLng{Latitude: 52.52, Longitude: -13.4},
+		"at":       time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+	}
+	want := map[string]any{
+		"author":   firestore.Reference("authors/jane"),
+		"location": &latlng.LatLng{Latitude: 52.52, Longitude: -13.4},
+		"at":       time.Date(2025, 1, 2, 2, 4, 5, 0, time.UTC),
+	}
+
+	if got := normalize(raw); !reflect.DeepEqual(got, want) {
+		t.Errorf("normalize() = %#v, want %#v", got, want)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	snap := NewSnapshot()
+	snap.Documents["mods/abc123"] = sampleDocument()
+	snap.Documents["meta/repos"] = map[string]any{"list": []any{"owner/repo"}}
+
+	file := filepath.Join(t.TempDir(), "backup.tar.gz")
+	manifest, err := WriteArchive(file, snap, "1.2.3", "test-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Format != archiveFormat {
+		t.Errorf("manifest format = %d, want %d", manifest.Format, archiveFormat)
+	}
+
+	restored, read, err := ReadArchive(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.ProjectID != "test-project" || read.Counts["mods"] != 1 {
+		t.Errorf("manifest = %+v", read)
+	}
+	if changes := Compare(snap, restored); len(changes) > 0 {
+		t.Errorf("archive round trip reported changes: %+v", changes)
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// AnnotationNoConfig marks a command (and its subcommands) as able to run when the file named by --config does not exist
+const AnnotationNoConfig = "pdt/no-config"
+
+// DefaultCollections holds the default collection and meta document paths
+var DefaultCollections = map[string]string{
+	"audit":             "audit",
+	"meta.modinfo":      "meta/modinfo",
+	"meta.repositories": "meta/repos",
+	"meta.status":       "meta/status",
+	"meta.toolinfo":     "meta/toolinfo",
+	"mods":              "mods",
+	"tools":             "tools",
+}
+
+// NoConfig reports whether cmd or one of its parents is annotated with AnnotationNoConfig
+func NoConfig(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[AnnotationNoConfig] == "true" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExpandHome expands a leading ~/ in path to the user's home directory
+func ExpandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/viper"
+)
+
+// configName is the base name of the config file in the system and XDG directories
+const configName = "config"
+
+// homeConfigName is the base name of the config file in the home and working directories
+const homeConfigName = ".pdtconfig"
+
+// layer is a directory searched for a config file with a given base name
+type layer struct {
+	dir  string
+	name string
+}
+
+// layers returns the places searched for config files, lowest precedence first:
+// system-wide, the XDG config directory, $HOME, then the working directory
+func layers() []layer {
+	var l []layer
+
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			l = append(l, layer{filepath.Join(dir, "pdt"), configName})
+		}
+	} else {
+		l = append(l, layer{"/etc/pdt", configName})
+	}
+
+	if dir, err := os.UserConfigDir(); err == nil {
+		l = append(l, layer{filepath.Join(dir, "pdt"), configName})
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		l = append(l, layer{home, homeConfigName})
+	}
+	if wd, err := os.Getwd(); err == nil {
+		l = append(l, layer{wd, homeConfigName})
+	}
+
+	return l
+}
+
+// SearchPaths returns the config file patterns that are searched, lowest precedence first
+func SearchPaths() []string {
+	var paths []string
+	for _, l := range layers() {
+		paths = append(paths, filepath.Join(l.dir, l.name+".*"))
+	}
+
+	return paths
+}
+
+// Load reads the config. With an explicit file only that file is read; otherwise every
+// discovered layer is merged over the previous ones. Finding no config file at all is not an error,
+// so commands that do not need the database can run without one.
+func Load(explicit string) error {
+	if explicit != "" {
+		viper.SetConfigFile(explicit)
+		if err := viper.ReadInConfig(); err != nil {
+			return err
+		}
+		return RecordFile(explicit)
+	}
+
+	seen := make(map[string]bool)
+	for _, l := range layers() {
+		path := find(l)
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		viper.SetConfigFile(path)
+		if err := viper.MergeInConfig(); err != nil {
+			return fmt.Errorf("unable to read config file %s: %w", path, err)
+		}
+		if err := RecordFile(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// find returns the first config file in a layer with an extension viper can read (json, yaml, toml, ...)
+func find(l layer) string {
+	for _, ext := range viper.SupportedExts {
+		path := filepath.Join(l.dir, l.name+"."+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// ProfileEnv is the environment variable used to select a profile when --profile is not given
+const ProfileEnv = "PDT_PROFILE"
+
+// ConfirmProfileEnv can be set to the active profile name to confirm writes to a protected profile without a prompt
+const ConfirmProfileEnv = "PDT_CONFIRM_PROFILE"
+
+// credentialKeys each select a source of Firebase credentials
+var credentialKeys = []string{"firebase.credentials", "firebase.credentials_file"}
+
+var (
+	active    string
+	confirmed bool
+	// cleared lists the top-level keys shadowed by the active profile
+	cleared []string
+)
+
+// SelectProfile merges the profiles.<name> section of the config over the top-level settings.
+// A profile that sets any credential source replaces all of them, so it never connects with
+// credentials that belong to the top-level project.
+func SelectProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	key := "profiles." + name
+	if !viper.IsSet(key) {
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(Profiles(), ", "))
+	}
+
+	// protected describes the profile itself rather than a setting. The map is viper's own, so it is copied first.
+	settings := maps.Clone(viper.GetStringMap(key))
+	delete(settings, "protected")
+
+	if err := viper.MergeConfigMap(settings); err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(credentialKeys, func(k string) bool { return viper.IsSet(key + "." + k) }) {
+		for _, k := range credentialKeys {
+			if viper.IsSet(key + "." + k) {
+				// Set rather than merge, so inline credentials don't keep fields of the top-level ones
+				viper.Set(k, viper.Get(key+"."+k))
+				continue
+			}
+
+			// An empty value shadows the top-level setting along with every key nested under it
+			viper.Set(k, "")
+			cleared = append(cleared, k)
+		}
+	}
+
+	active = name
+	return nil
+}
+
+// Profiles returns the names of every configured profile, sorted
+func Profiles() []string {
+	var names []string
+	for name := range viper.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns the name of the active profile, or an empty string when none is selected
+func Profile() string {
+	return active
+}
+
+// Protected reports whether the active profile is marked protected: true
+func Protected() bool {
+	return active != "" && viper.GetBool("profiles."+active+".protected")
+}
+
+// ConfirmWrite asks for explicit confirmation before the first write to a protected profile.
+// The confirmation is remembered for the rest of the run.
+func ConfirmWrite() error {
+	if !Protected() || confirmed {
+		return nil
+	}
+
+	if os.Getenv(ConfirmProfileEnv) == active {
+		confirmed = true
+		return nil
+	}
+
+	pterm.Warning.Printfln("Profile %q is protected.", active)
+	answer, err := pterm.DefaultInteractiveTextInput.Show(fmt.Sprintf("Type %q to allow writes to it", active))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(answer) != active {
+		return errors.New("write to protected profile was not confirmed")
+	}
+
+	confirmed = true
+	return nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const profileConfig = `
+firebase:
+  project_id: prod-project
+  credentials:
+    type: service_account
+    project_id: prod-project
+    private_key: prod-key
+profiles:
+  staging:
+    protected: true
+    firebase:
+      project_id: staging-project
+      credentials_file: /etc/pdt/staging.json
+  inline:
+    firebase:
+      credentials:
+        type: service_account
+        project_id: inline-project
+  plain:
+    operator: ci
+`
+
+func loadProfileConfig(t *testing.T) {
+	t.Helper()
+
+	viper.Reset()
+	active, confirmed, cleared = "", false, nil
+	t.Cleanup(func() {
+		viper.Reset()
+		active, confirmed, cleared = "", false, nil
+	})
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(profileConfig)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSelectProfileReplacesCredentials(t *testing.T) {
+	loadProfileConfig(t)
+
+	if err := SelectProfile("staging"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := viper.GetString("firebase.credentials_file"); got != "/etc/pdt/staging.json" {
+		t.Errorf("credentials_file = %q, want the profile's file", got)
+	}
+	if got := viper.GetStringMap("firebase.credentials"); len(got) > 0 {
+		t.Errorf("firebase.credentials = %v, want the top-level credentials cleared", got)
+	}
+	if got := viper.GetString("firebase.credentials.private_key"); got != "" {
+		t.Errorf("firebase.credentials.private_key = %q, want it cleared", got)
+	}
+	if got := viper.GetString("firebase.project_id"); got != "staging-project" {
+		t.Errorf("project_id = %q, want staging-project", got)
+	}
+}
+
+func TestSelectProfileInlineCredentials(t *testing.T) {
+	loadProfileConfig(t)
+
+	if err := SelectProfile("inline"); err != nil {
+		t.Fatal(err)
+	}
+
+	creds := viper.GetStringMap("firebase.credentials")
+	if creds["project_id"] != "inline-project" {
+		t.Errorf("credentials project_id = %v, want inline-project", creds["project_id"])
+	}
+	if _, ok := creds["private_key"]; ok {
+		t.Error("profile credentials kept the top-level private_key")
+	}
+}
+
+func TestSelectProfileKeepsCredentialsWhenUnset(t *testing.T) {
+	loadProfileConfig(t)
+
+	if err := SelectProfile("plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := viper.GetString("firebase.credentials.project_id"); got != "prod-project" {
+		t.Errorf("credentials project_id = %q, want the top-level credentials", got)
+	}
+	if got := viper.GetString("operator"); got != "ci" {
+		t.Errorf("operator = %q, want ci", got)
+	}
+}
+
+func TestSelectProfileProtected(t *testing.T) {
+	loadProfileConfig(t)
+
+	if err := SelectProfile("staging"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !Protected() {
+		t.Error("Protected() = false, want true")
+	}
+	if viper.IsSet("protected") {
+		t.Error("protected was merged into the top-level config")
+	}
+	for _, err := range Validate() {
+		if strings.Contains(err.Error(), "unknown key") {
+			t.Errorf("Validate() = %v", err)
+		}
+	}
+}
+
+func TestSelectProfileUnknown(t *testing.T) {
+	loadProfileConfig(t)
+
+	if err := SelectProfile("missing"); err == nil {
+		t.Error("SelectProfile(missing) succeeded, want an error")
+	}
+}
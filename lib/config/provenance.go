@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix is the prefix of environment variables that override config keys (e.g. PDT_GITHUB_TOKEN)
+const EnvPrefix = "PDT"
+
+// Redacted replaces the value of secret settings in output
+const Redacted = "[REDACTED]"
+
+// secretKeys are key names (the last segment of a config key) whose values are never printed
+var secretKeys = []string{"private_key", "private_key_id", "client_secret", "token", "password", "secret"}
+
+// flagKeys maps config keys that are set from command line flags to their flag names
+var flagKeys = map[string]string{
+	"dryrun":       "dryrun",
+	"color":        "no-color",
+	"verbosity":    "verbose",
+	"log.format":   "log-format",
+	"log.file":     "log-file",
+	"log.level":    "log-level",
+	"metrics.file": "metrics-file",
+	"metrics.addr": "metrics-addr",
+	"trace":        "trace",
+}
+
+// Setting is a single effective config value and where it came from
+type Setting struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// file is a config file that was loaded, with the keys it set
+type file struct {
+	path string
+	keys []string
+}
+
+var files []file
+
+// RecordFile remembers the keys set by a loaded config file, for reporting where settings came from
+func RecordFile(path string) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+
+	files = append(files, file{path: path, keys: v.AllKeys()})
+	return nil
+}
+
+// Files returns the config files that were loaded, in the order they were merged
+func Files() []string {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.path)
+	}
+
+	return paths
+}
+
+// EnvName returns the environment variable that overrides a config key
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.NewReplacer(".", "_").Replace(key))
+}
+
+// IsSecret reports whether the value of a config key must be redacted
+func IsSecret(key string) bool {
+	name := key[strings.LastIndex(key, ".")+1:]
+	return slices.Contains(secretKeys, strings.ToLower(name))
+}
+
+// Effective returns every effective setting, except profile definitions, with secrets redacted
+func Effective(cmd *cobra.Command) []Setting {
+	keys := viper.AllKeys()
+	for _, key := range knownKeys() {
+		if !slices.Contains(keys, key) && os.Getenv(EnvName(key)) != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var settings []Setting
+	for _, key := range keys {
+		if strings.HasPrefix(key, "profiles.") {
+			continue
+		}
+
+		var value any = viper.Get(key)
+		if IsSecret(key) {
+			value = Redacted
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: Source(cmd, key)})
+	}
+
+	return settings
+}
+
+// Source describes where the effective value of a config key came from
+func Source(cmd *cobra.Command, key string) string {
+	if name, ok := flagKeys[key]; ok {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+			return "flag --" + name
+		}
+	}
+
+	if _, ok := os.LookupEnv(EnvName(key)); ok {
+		return "env " + EnvName(key)
+	}
+
+	if slices.Contains(cleared, key) {
+		return fmt.Sprintf("cleared by profile %q", active)
+	}
+	if active != "" && viper.IsSet("profiles."+active+"."+key) {
+		return fmt.Sprintf("profile %q", active)
+	}
+
+	for i := len(files) - 1; i >= 0; i-- {
+		if slices.Contains(files[i].keys, key) {
+			return "file " + files[i].path
+		}
+	}
+
+	return "default"
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// knownPrefixes are config sections whose keys are not individually validated
+var knownPrefixes = []string{"firebase.credentials."}
+
+// knownKeys returns every config key pdt understands
+func knownKeys() []string {
+	keys := []string{
+		"firebase.credentials",
+		"firebase.credentials_file",
+		"firebase.project_id",
+		"github.token",
+		"github.base_url",
+		"github.raw_url",
+		"operator",
+		"log.format",
+		"log.file",
+		"log.level",
+		"log.max_size",
+		"log.max_backups",
+		"metrics.file",
+		"metrics.addr",
+		"trace",
+		"dryrun",
+		"color",
+		"verbosity",
+	}
+	for k := range DefaultCollections {
+		keys = append(keys, "firebase.collections."+k)
+	}
+
+	return keys
+}
+
+// Validate reports unknown keys and missing required keys in the effective configuration
+func Validate() []error {
+	var errs []error
+
+	known := knownKeys()
+	isKnown := func(key string) bool {
+		if slices.Contains(known, key) {
+			return true
+		}
+		for _, prefix := range knownPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, key := range viper.AllKeys() {
+		if rest, ok := strings.CutPrefix(key, "profiles."); ok {
+			// profiles.<name>.<key>
+			_, sub, _ := strings.Cut(rest, ".")
+			if sub != "protected" && !isKnown(sub) {
+				errs = append(errs, fmt.Errorf("unknown key %q", key))
+			}
+			continue
+		}
+		if !isKnown(key) {
+			errs = append(errs, fmt.Errorf("unknown key %q", key))
+		}
+	}
+
+	for k := range DefaultCollections {
+		// The audit collection falls back to its default
+		if key := "firebase.collections." + k; k != "audit" && viper.GetString(key) == "" {
+			errs = append(errs, fmt.Errorf("missing required key %q", key))
+		}
+	}
+
+	if file := viper.GetString("firebase.credentials_file"); file != "" {
+		if _, err := os.Stat(ExpandHome(file)); err != nil {
+			errs = append(errs, fmt.Errorf("firebase.credentials_file: %w", err))
+		}
+	}
+
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errs
+}
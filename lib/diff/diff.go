@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Line is a single line of a diff
+type Line struct {
+	Op   byte // ' ', '-' or '+'
+	Text string
+}
+
+// String renders the line in unified diff notation
+func (l Line) String() string {
+	return string(l.Op) + " " + l.Text
+}
+
+// Lines returns a line-based diff between a and b using the longest common subsequence
+func Lines(a, b string) []Line {
+	x := splitLines(a)
+	y := splitLines(b)
+
+	// lcs[i][j] holds the length of the LCS of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []Line
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, Line{' ', x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{'-', x[i]})
+			i++
+		default:
+			lines = append(lines, Line{'+', y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, Line{'-', x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, Line{'+', y[j]})
+	}
+
+	return lines
+}
+
+// Changed reports whether any line in the diff was added or removed
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != ' ' {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
+
+// FieldChange describes a single field that differs between two documents
+type FieldChange struct {
+	Field  string `firestore:"field" json:"field"`
+	Before any    `firestore:"before" json:"before,omitempty"`
+	After  any    `firestore:"after" json:"after,omitempty"`
+}
+
+// Fields returns the field-level differences between two documents, recursing into nested maps.
+// Nested fields are reported with dotted paths and the result is sorted by field.
+func Fields(before, after map[string]any) []FieldChange {
+	var changes []FieldChange
+	fields("", before, after, &changes)
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func fields(prefix string, before, after map[string]any, changes *[]FieldChange) {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+
+	for k := range keys {
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+
+		bm, bIsMap := b.(map[string]any)
+		am, aIsMap := a.(map[string]any)
+		if bIsMap && aIsMap {
+			fields(prefix+k+".", bm, am, changes)
+			continue
+		}
+
+		if inBefore && inAfter && reflect.DeepEqual(a, b) {
+			continue
+		}
+		*changes = append(*changes, FieldChange{Field: prefix + k, Before: b, After: a})
+	}
+}
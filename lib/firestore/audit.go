@@ -0,0 +1,188 @@
+package firestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	gfs "cloud.google.com/go/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/diff"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/operator"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/api/iterator"
+)
+
+// AuditEntry records a single mutation of the database
+type AuditEntry struct {
+	ID        string             `firestore:"-" json:"id"`
+	Operator  string             `firestore:"operator" json:"operator"`
+	Command   string             `firestore:"command" json:"command"`
+	Timestamp time.Time          `firestore:"timestamp" json:"timestamp"`
+	Path      string             `firestore:"path" json:"path"`
+	Changes   []diff.FieldChange `firestore:"changes" json:"changes"`
+}
+
+// AuditFilter narrows down the audit entries returned by AuditLog
+type AuditFilter struct {
+	Path     string
+	Operator string
+	Since    time.Time
+	Limit    int
+}
+
+// auditCollection returns the configured audit collection path
+func auditCollection() string {
+	if collection := viper.GetString("firebase.collections.audit"); collection != "" {
+		return collection
+	}
+
+	return "audit"
+}
+
+// writeAudited runs write in a transaction and records an audit entry for ref in the same transaction,
+// so a mutation is never stored without its audit trail
+func writeAudited(ctx context.Context, ref *gfs.DocumentRef, before, after any, write func(*gfs.Transaction) error) error {
+	if err := config.ConfirmWrite(); err != nil {
+		return err
+	}
+
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	err = client.RunTransaction(ctx, func(ctx context.Context, tx *gfs.Transaction) error {
+		if err := write(tx); err != nil {
+			return err
+		}
+
+		return recordAudit(client, tx, ref, before, after)
+	})
+	if err != nil {
+		metrics.Error(subsystem, err)
+		return err
+	}
+
+	// The document and its audit entry
+	metrics.Add(metrics.FirestoreWrites, 2)
+	return nil
+}
+
+// recordAudit adds an audit entry describing the change of ref from before to after to the transaction.
+// Writes to the audit collection itself (e.g. when it is restored from a backup) are not audited.
+func recordAudit(client *gfs.Client, tx *gfs.Transaction, ref *gfs.DocumentRef, before, after any) error {
+	if !audited(ref) {
+		return nil
+	}
+
+	entry := AuditEntry{
+		Operator:  operator.Name(),
+		Command:   strings.Join(os.Args, " "),
+		Timestamp: time.Now().UTC(),
+		Path:      documentPath(ref),
+		Changes:   diff.Fields(toMap(before), toMap(after)),
+	}
+	auditRef := client.Collection(auditCollection()).NewDoc()
+
+	logger.For(subsystem).Debug(fmt.Sprintf("Recording audit entry %q for %q", auditRef.ID, entry.Path))
+	return tx.Create(auditRef, entry)
+}
+
+// audited reports whether writes to ref get an audit entry
+func audited(ref *gfs.DocumentRef) bool {
+	return path.Dir(documentPath(ref)) != auditCollection()
+}
+
+// AuditLog returns the audit entries matching filter, newest first
+func AuditLog(ctx context.Context, filter AuditFilter) ([]AuditEntry, error) {
+	ctx, span := tracing.Start(ctx, "firestore.AuditLog", attribute.String("path", filter.Path))
+	defer span.End()
+
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ordering by timestamp and a range on the same field only need Firestore's single-field index
+	query := client.Collection(auditCollection()).OrderBy("timestamp", gfs.Desc)
+	if !filter.Since.IsZero() {
+		query = query.Where("timestamp", ">=", filter.Since)
+	}
+
+	// Equality filters on other fields would need a composite index for every combination, so path and operator
+	// are matched as the newest entries stream in, stopping as soon as the limit is reached
+	clientFilter := filter.Path != "" || filter.Operator != ""
+	if !clientFilter && filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	logger.For(subsystem).Info(fmt.Sprintf("Fetching audit entries from %q", auditCollection()))
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	entries := make([]AuditEntry, 0)
+	for filter.Limit <= 0 || len(entries) < filter.Limit {
+		docsnap, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Inc(metrics.FirestoreReads)
+
+		var entry AuditEntry
+		if err := docsnap.DataTo(&entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", docsnap.Ref.Path, err)
+		}
+		if filter.Path != "" && entry.Path != filter.Path {
+			continue
+		}
+		if filter.Operator != "" && entry.Operator != filter.Operator {
+			continue
+		}
+
+		entry.ID = docsnap.Ref.ID
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// documentPath returns the path of a document relative to the database root (e.g. "mods/abc123")
+func documentPath(ref *gfs.DocumentRef) string {
+	if _, path, ok := strings.Cut(ref.Path, "/documents/"); ok {
+		return path
+	}
+
+	return ref.Path
+}
+
+// toMap converts a document into a generic map using its JSON field names
+func toMap(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	if m, ok := v.(map[string]any); ok {
+		return m
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var m map[string]any
+	_ = json.Unmarshal(data, &m)
+	return m
+}
@@ -0,0 +1,121 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	gfs "cloud.google.com/go/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConfiguredPaths returns every collection and meta document path configured under firebase.collections, sorted
+func ConfiguredPaths() []string {
+	var paths []string
+	for _, key := range viper.AllKeys() {
+		if !strings.HasPrefix(key, "firebase.collections.") {
+			continue
+		}
+		if path := viper.GetString(key); path != "" && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// IsDocumentPath reports whether path addresses a document (an even number of segments) rather than a collection
+func IsDocumentPath(path string) bool {
+	return len(strings.Split(strings.Trim(path, "/"), "/"))%2 == 0
+}
+
+// Reference points at another document by its path relative to the database root (e.g. "mods/abc123"),
+// so it can be compared and archived without a client. Tx writes turn it back into a document reference.
+type Reference string
+
+// ReferenceOf returns the Reference for a document reference read from Firestore
+func ReferenceOf(ref *gfs.DocumentRef) Reference {
+	return Reference(documentPath(ref))
+}
+
+// resolveReferences returns a copy of v in which every Reference is replaced by a document reference of client
+func resolveReferences(client *gfs.Client, v any) (any, error) {
+	switch v := v.(type) {
+	case Reference:
+		ref := client.Doc(string(v))
+		if ref == nil {
+			return nil, fmt.Errorf("%q is not a document path", v)
+		}
+		return ref, nil
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			r, err := resolveReferences(client, e)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			m[k] = r
+		}
+		return m, nil
+	case []any:
+		l := make([]any, len(v))
+		for i, e := range v {
+			r, err := resolveReferences(client, e)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+			l[i] = r
+		}
+		return l, nil
+	default:
+		return v, nil
+	}
+}
+
+// ReadDocuments fetches the raw data of a single document or of every document in a collection, keyed by document path
+func ReadDocuments(ctx context.Context, path string) (map[string]map[string]any, error) {
+	ctx, span := tracing.Start(ctx, "firestore.ReadDocuments", attribute.String("path", path))
+	defer span.End()
+
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string]map[string]any)
+
+	if IsDocumentPath(path) {
+		logger.For(subsystem).Info(fmt.Sprintf("Reading document %q", path))
+		docsnap, err := client.Doc(path).Get(ctx)
+		if status.Code(err) == codes.NotFound {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Inc(metrics.FirestoreReads)
+		docs[path] = docsnap.Data()
+		return docs, nil
+	}
+
+	logger.For(subsystem).Info(fmt.Sprintf("Reading collection %q", path))
+	docsnaps, err := client.Collection(path).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	metrics.Add(metrics.FirestoreReads, float64(len(docsnaps)))
+	for _, docsnap := range docsnaps {
+		docs[documentPath(docsnap.Ref)] = docsnap.Data()
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,232 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	gfs "cloud.google.com/go/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrConflict is returned when a document was modified after it was read
+var ErrConflict = errors.New("document was modified since it was read")
+
+// Collection returns the configured collection path for the given document kind
+func Collection(kind string) (string, error) {
+	var key string
+	switch kind {
+	case models.KindMod:
+		key = "firebase.collections.mods"
+	case models.KindTool:
+		key = "firebase.collections.tools"
+	default:
+		_, err := models.New(kind)
+		return "", err
+	}
+
+	collection := viper.GetString(key)
+	if collection == "" {
+		return "", fmt.Errorf("no %s collection specified in config (%s)", kind, key)
+	}
+
+	return collection, nil
+}
+
+// GetEntry fetches a single mod or tool document along with its last update time
+func GetEntry(ctx context.Context, kind, id string) (models.Document, time.Time, error) {
+	ctx, span := tracing.Start(ctx, "firestore.GetEntry", attribute.String("kind", kind), attribute.String("id", id))
+	defer span.End()
+
+	ref, err := entryRef(kind, id)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	logger.For(subsystem).Info(fmt.Sprintf("Fetching %s %q", kind, ref.Path))
+
+	docsnap, err := ref.Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, time.Time{}, fmt.Errorf("%s %q not found", kind, id)
+		}
+		return nil, time.Time{}, err
+	}
+	metrics.Inc(metrics.FirestoreReads)
+
+	doc, _ := models.New(kind)
+	if err := docsnap.DataTo(doc); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return doc, docsnap.UpdateTime, nil
+}
+
+// UpdateEntry writes the fields that differ between before and after.
+// The write only succeeds if the document has not been updated since lastUpdate, otherwise ErrConflict is returned.
+func UpdateEntry(ctx context.Context, kind, id string, before, after models.Document, lastUpdate time.Time) error {
+	ctx, span := tracing.Start(ctx, "firestore.UpdateEntry", attribute.String("kind", kind), attribute.String("id", id))
+	defer span.End()
+
+	ref, err := entryRef(kind, id)
+	if err != nil {
+		return err
+	}
+
+	updates := fieldUpdates(models.Fields(before), models.Fields(after))
+	if len(updates) == 0 {
+		logger.For(subsystem).Info(fmt.Sprintf("No changes to write for %q", ref.Path))
+		return nil
+	}
+
+	logger.For(subsystem).Info(fmt.Sprintf("Updating %d field(s) of %q", len(updates), ref.Path))
+	err = writeAudited(ctx, ref, before, after, func(tx *gfs.Transaction) error {
+		return tx.Update(ref, updates, gfs.LastUpdateTime(lastUpdate))
+	})
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return fmt.Errorf("%s %q: %w", kind, id, ErrConflict)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func entryRef(kind, id string) (*gfs.DocumentRef, error) {
+	collection, err := Collection(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Collection(collection).Doc(id), nil
+}
+
+// fieldUpdates builds the Firestore updates needed to turn the before fields into the after fields
+func fieldUpdates(before, after map[string]any) []gfs.Update {
+	var updates []gfs.Update
+
+	for k, v := range after {
+		if old, ok := before[k]; !ok || !reflect.DeepEqual(old, v) {
+			updates = append(updates, gfs.Update{FieldPath: gfs.FieldPath{k}, Value: v})
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			updates = append(updates, gfs.Update{FieldPath: gfs.FieldPath{k}, Value: gfs.Delete})
+		}
+	}
+
+	return updates
+}
+
+// Entry is a mod or tool document along with its Firestore metadata
+type Entry struct {
+	ID         string
+	Doc        models.Document
+	UpdateTime time.Time
+}
+
+// Entries fetches every document of the given kind
+func Entries(ctx context.Context, kind string) ([]Entry, error) {
+	ctx, span := tracing.Start(ctx, "firestore.Entries", attribute.String("kind", kind))
+	defer span.End()
+
+	collection, err := Collection(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	logger.For(subsystem).Info(fmt.Sprintf("Fetching %s documents from %q", kind, collection))
+
+	docsnaps, err := client.Collection(collection).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	metrics.Add(metrics.FirestoreReads, float64(len(docsnaps)))
+
+	entries := make([]Entry, 0, len(docsnaps))
+	for _, docsnap := range docsnaps {
+		doc, _ := models.New(kind)
+		if err := docsnap.DataTo(doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", docsnap.Ref.Path, err)
+		}
+		entries = append(entries, Entry{ID: docsnap.Ref.ID, Doc: doc, UpdateTime: docsnap.UpdateTime})
+	}
+
+	return entries, nil
+}
+
+// FindEntry looks up a document by ID in every document collection and returns its kind
+func FindEntry(ctx context.Context, id string) (string, models.Document, time.Time, error) {
+	ctx, span := tracing.Start(ctx, "firestore.FindEntry", attribute.String("id", id))
+	defer span.End()
+
+	for _, kind := range models.Kinds {
+		ref, err := entryRef(kind, id)
+		if err != nil {
+			return "", nil, time.Time{}, err
+		}
+
+		docsnap, err := ref.Get(ctx)
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return "", nil, time.Time{}, err
+		}
+		metrics.Inc(metrics.FirestoreReads)
+
+		doc, _ := models.New(kind)
+		if err := docsnap.DataTo(doc); err != nil {
+			return "", nil, time.Time{}, err
+		}
+		return kind, doc, docsnap.UpdateTime, nil
+	}
+
+	return "", nil, time.Time{}, fmt.Errorf("no mod or tool with ID %q", id)
+}
+
+// CreateEntry adds a new document of the given kind and returns its generated ID
+func CreateEntry(ctx context.Context, kind string, doc models.Document) (string, error) {
+	ctx, span := tracing.Start(ctx, "firestore.CreateEntry", attribute.String("kind", kind))
+	defer span.End()
+
+	collection, err := Collection(kind)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := getClient()
+	if err != nil {
+		return "", err
+	}
+
+	ref := client.Collection(collection).NewDoc()
+	logger.For(subsystem).Info(fmt.Sprintf("Creating %q", ref.Path))
+	err = writeAudited(ctx, ref, nil, doc, func(tx *gfs.Transaction) error {
+		return tx.Create(ref, doc)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return ref.ID, nil
+}
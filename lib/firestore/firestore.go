@@ -3,44 +3,178 @@ package firestore
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"os"
 
+	"cloud.google.com/go/auth"
 	"cloud.google.com/go/auth/credentials"
 	gfs "cloud.google.com/go/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
 	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
 	"github.com/spf13/viper"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-var fsClient *gfs.Client
+// subsystem names this package in log records
+const subsystem = "firestore"
+
+var (
+	fsClient  *gfs.Client
+	projectID string
+)
+
+var scopes = []string{"https://www.googleapis.com/auth/cloud-platform"}
 
 func getClient() (*gfs.Client, error) {
 	if fsClient != nil {
 		return fsClient, nil
 	}
 
-	logger.Log.Info("Initializing Firestore client...")
+	logger.For(subsystem).Info("Initializing Firestore client...")
 
-	projectID := viper.GetString("firebase.credentials.project_id")
-	credsJson, err := json.Marshal(viper.Get("firebase.credentials"))
+	client, id, err := newClient(context.Background(), viper.GetViper())
 	if err != nil {
-		log.Panic(err)
+		return nil, err
 	}
+	fsClient, projectID = client, id
+
+	return fsClient, nil
+}
 
-	creds, err := credentials.DetectDefault(&credentials.DetectOptions{
-		Scopes:          []string{"https://www.googleapis.com/auth/cloud-platform"},
-		CredentialsJSON: credsJson,
-	})
+// newClient connects to the project described by the credentials and project ID settings of v
+func newClient(ctx context.Context, v *viper.Viper) (*gfs.Client, string, error) {
+	creds, err := detectCredentials(v)
 	if err != nil {
-		fmt.Println("creds: ", credsJson)
-		log.Panic(err)
+		return nil, "", err
 	}
 
-	fsClient, err := gfs.NewClient(context.Background(), projectID, option.WithAuthCredentials(creds))
+	id := configuredProjectID(v)
+	if id == "" {
+		if id, err = creds.ProjectID(ctx); err != nil {
+			return nil, "", err
+		}
+	}
+	if id == "" {
+		return nil, "", errors.New("unable to determine the Firebase project ID, set firebase.project_id in the config")
+	}
+
+	client, err := gfs.NewClient(ctx, id, option.WithAuthCredentials(creds))
 	if err != nil {
-		log.Panic(err)
+		return nil, "", err
 	}
 
-	return fsClient, nil
+	return client, id, nil
+}
+
+// detectCredentials finds service account credentials, in order of precedence, from:
+// the firebase.credentials config section, the file named by firebase.credentials_file,
+// or Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS, gcloud, or the metadata server)
+func detectCredentials(v *viper.Viper) (*auth.Credentials, error) {
+	// A profile clears the top-level credentials by setting them empty, so check for content rather than IsSet
+	if inline := v.GetStringMap("firebase.credentials"); len(inline) > 0 {
+		logger.For(subsystem).Info("Using credentials from firebase.credentials")
+
+		credsJson, err := json.Marshal(inline)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode firebase.credentials: %w", err)
+		}
+
+		creds, err := credentials.DetectDefault(&credentials.DetectOptions{
+			Scopes:          scopes,
+			CredentialsJSON: credsJson,
+		})
+		if err != nil {
+			// The error may quote the credentials it failed to parse, so never let it carry the private key
+			return nil, errors.New("unable to load credentials from firebase.credentials: " + logger.Redact(err.Error()))
+		}
+
+		return creds, nil
+	}
+
+	if file := credentialsFile(v); file != "" {
+		logger.For(subsystem).Info(fmt.Sprintf("Using credentials from %s", file))
+
+		creds, err := credentials.DetectDefault(&credentials.DetectOptions{
+			Scopes:          scopes,
+			CredentialsFile: file,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to load credentials from %s: %w", file, err)
+		}
+
+		return creds, nil
+	}
+
+	logger.For(subsystem).Info("Using Application Default Credentials")
+	creds, err := credentials.DetectDefault(&credentials.DetectOptions{Scopes: scopes})
+	if err != nil {
+		return nil, fmt.Errorf("no credentials configured (set firebase.credentials_file or GOOGLE_APPLICATION_CREDENTIALS): %w", err)
+	}
+
+	return creds, nil
+}
+
+// credentialsFile returns the configured credentials file path with a leading ~ expanded
+func credentialsFile(v *viper.Viper) string {
+	return config.ExpandHome(v.GetString("firebase.credentials_file"))
+}
+
+// ProjectID returns the Firebase project ID. An explicit firebase.project_id takes precedence
+// over the project_id of the configured credentials.
+func ProjectID() string {
+	if projectID != "" {
+		return projectID
+	}
+
+	return configuredProjectID(viper.GetViper())
+}
+
+// configuredProjectID returns the project ID set in v or in the credentials it names, without connecting
+func configuredProjectID(v *viper.Viper) string {
+	if id := v.GetString("firebase.project_id"); id != "" {
+		return id
+	}
+	if id := v.GetString("firebase.credentials.project_id"); id != "" {
+		return id
+	}
+
+	// Read project_id from the credentials file, without the rest of its contents
+	if file := credentialsFile(v); file != "" {
+		var creds struct {
+			ProjectID string `json:"project_id"`
+		}
+		if data, err := os.ReadFile(file); err == nil && json.Unmarshal(data, &creds) == nil {
+			return creds.ProjectID
+		}
+	}
+
+	return ""
+}
+
+// Ping checks that the database described by v alone can be reached with its credentials by reading the
+// status document. It does not touch the client used by the rest of the run.
+func Ping(ctx context.Context, v *viper.Viper) error {
+	ctx, span := tracing.Start(ctx, "firestore.Ping")
+	defer span.End()
+
+	path := v.GetString("firebase.collections.meta." + MetaStatus)
+	if path == "" {
+		return fmt.Errorf("no %s document specified (firebase.collections.meta.%s)", MetaStatus, MetaStatus)
+	}
+
+	client, _, err := newClient(ctx, v)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if _, err := client.Doc(path).Get(ctx); err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,46 @@
+package firestore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
+	"github.com/spf13/viper"
+)
+
+func TestProjectIDFollowsProfileCredentials(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "staging.json")
+	if err := os.WriteFile(file, []byte(`{"type": "service_account", "project_id": "staging-project"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+firebase:
+  credentials:
+    type: service_account
+    project_id: prod-project
+profiles:
+  staging:
+    firebase:
+      credentials_file: ` + file + `
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.SelectProfile("staging"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ProjectID(); got != "staging-project" {
+		t.Errorf("ProjectID() = %q, want the project of the profile's credentials file", got)
+	}
+	if inline := viper.GetStringMap("firebase.credentials"); len(inline) > 0 {
+		t.Errorf("inline credentials %v would take precedence over the profile's credentials file", inline)
+	}
+}
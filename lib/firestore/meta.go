@@ -0,0 +1,175 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	gfs "cloud.google.com/go/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Meta document keys, as configured under firebase.collections.meta
+const (
+	MetaModinfo  = "modinfo"
+	MetaToolinfo = "toolinfo"
+	MetaRepos    = "repositories"
+	MetaStatus   = "status"
+)
+
+// MetaList fetches the "list" field of a meta document (e.g. the modinfo URLs)
+func MetaList(ctx context.Context, key string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "firestore.MetaList", attribute.String("key", key))
+	defer span.End()
+
+	list, err := readMeta(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return list.List, nil
+}
+
+// readMeta fetches a meta document, returning an empty one if it does not exist
+func readMeta(ctx context.Context, key string) (*repoList, error) {
+	ref, err := metaRef(key)
+	if err != nil {
+		return nil, err
+	}
+	logger.For(subsystem).Info(fmt.Sprintf("Fetching %s list from %q", key, ref.Path))
+
+	var list repoList
+	docsnap, err := ref.Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &list, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	metrics.Inc(metrics.FirestoreReads)
+
+	if err := docsnap.DataTo(&list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// MetaLists holds the lists stored in a meta document. Inactive is only used by the repositories document.
+type MetaLists struct {
+	List     []string
+	Inactive []string
+}
+
+// SetMetaList replaces the "list" field of a meta document
+func SetMetaList(ctx context.Context, key string, list []string) error {
+	ctx, span := tracing.Start(ctx, "firestore.SetMetaList", attribute.String("key", key))
+	defer span.End()
+
+	return UpdateMetaLists(ctx, []string{key}, func(docs map[string]*MetaLists) error {
+		docs[key].List = list
+		return nil
+	})
+}
+
+// UpdateMetaLists reads the given meta documents in a transaction and passes their lists to update, which changes
+// them in place. Every document that changed is written back in the same transaction, along with its audit entry,
+// so a concurrent edit makes the transaction retry instead of being lost. update runs again on every retry.
+func UpdateMetaLists(ctx context.Context, keys []string, update func(docs map[string]*MetaLists) error) error {
+	ctx, span := tracing.Start(ctx, "firestore.UpdateMetaLists", attribute.StringSlice("keys", keys))
+	defer span.End()
+
+	paths := make(map[string]string, len(keys))
+	for _, key := range keys {
+		path, err := MetaPath(key)
+		if err != nil {
+			return err
+		}
+		paths[key] = path
+	}
+
+	_, err := RunTransaction(ctx, viper.GetBool("dryrun"), func(tx *Tx) error {
+		before := make(map[string]MetaLists, len(keys))
+		docs := make(map[string]*MetaLists, len(keys))
+		for _, key := range keys {
+			data, err := tx.Get(paths[key])
+			if err != nil {
+				return err
+			}
+
+			lists := MetaLists{List: stringList(data["list"]), Inactive: stringList(data["inactive"])}
+			before[key] = lists
+			docs[key] = &MetaLists{List: slices.Clone(lists.List), Inactive: slices.Clone(lists.Inactive)}
+		}
+
+		if err := update(docs); err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			fields := make(map[string]any)
+			if !slices.Equal(before[key].List, docs[key].List) {
+				fields["list"] = docs[key].List
+			}
+			if !slices.Equal(before[key].Inactive, docs[key].Inactive) {
+				fields["inactive"] = docs[key].Inactive
+			}
+			if len(fields) == 0 {
+				continue
+			}
+
+			logger.For(subsystem).Info(fmt.Sprintf("Writing %d %s entries to %q", len(docs[key].List), key, paths[key]))
+			if err := tx.Merge(paths[key], fields); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// stringList converts a raw Firestore array into a list of strings
+func stringList(v any) []string {
+	items, _ := v.([]any)
+
+	var list []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+
+	return list
+}
+
+// MetaPath returns the configured path of a meta document
+func MetaPath(key string) (string, error) {
+	path := viper.GetString("firebase.collections.meta." + key)
+	if path == "" {
+		return "", fmt.Errorf("no %s document specified in config (firebase.collections.meta.%s)", key, key)
+	}
+
+	return path, nil
+}
+
+func metaRef(key string) (*gfs.DocumentRef, error) {
+	path, err := MetaPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Doc(path), nil
+}
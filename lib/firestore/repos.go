@@ -5,13 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
 
 	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type repoList struct {
-	List []string `firestore:"list"`
+	List     []string `firestore:"list"`
+	Inactive []string `firestore:"inactive,omitempty"`
 }
 
 func (r *repoList) Add(repo string) {
@@ -48,7 +53,7 @@ func Repos() *repoList {
 	if repoCollection == "" {
 		log.Fatal("No repository collection specified in config")
 	}
-	logger.Log.Info(fmt.Sprintf("Fetching repositories from %q", repoCollection))
+	logger.For(subsystem).Info(fmt.Sprintf("Fetching repositories from %q", repoCollection))
 
 	client, err := getClient()
 	if err != nil {
@@ -68,3 +73,69 @@ func Repos() *repoList {
 
 	return repos
 }
+
+// AddRepos adds repositories to the meta repos list, skipping any that are already present
+func AddRepos(ctx context.Context, add ...string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "firestore.AddRepos", attribute.StringSlice("repos", add))
+	defer span.End()
+
+	var added []string
+	err := UpdateMetaLists(ctx, []string{MetaRepos}, func(docs map[string]*MetaLists) error {
+		current := &repoList{List: docs[MetaRepos].List}
+
+		added = nil
+		for _, repo := range add {
+			if slices.ContainsFunc(current.List, func(r string) bool { return strings.EqualFold(r, repo) }) {
+				logger.For(subsystem).Warn(fmt.Sprintf("Repository %q is already listed", repo))
+				continue
+			}
+			current.Add(repo)
+			added = append(added, repo)
+		}
+
+		docs[MetaRepos].List = current.List
+		return nil
+	})
+
+	return added, err
+}
+
+// RemoveRepos removes repositories from the meta repos list
+func RemoveRepos(ctx context.Context, remove ...string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "firestore.RemoveRepos", attribute.StringSlice("repos", remove))
+	defer span.End()
+
+	var removed []string
+	err := UpdateMetaLists(ctx, []string{MetaRepos}, func(docs map[string]*MetaLists) error {
+		current := &repoList{List: docs[MetaRepos].List}
+
+		removed = nil
+		for _, repo := range remove {
+			i := slices.IndexFunc(current.List, func(r string) bool { return strings.EqualFold(r, repo) })
+			if i < 0 {
+				logger.For(subsystem).Warn(fmt.Sprintf("Repository %q is not listed", repo))
+				continue
+			}
+			removed = append(removed, current.List[i])
+			current.Remove(current.List[i])
+		}
+
+		docs[MetaRepos].List = current.List
+		return nil
+	})
+
+	return removed, err
+}
+
+// RepoLists fetches the active repositories and those marked inactive
+func RepoLists(ctx context.Context) (active, inactive []string, err error) {
+	ctx, span := tracing.Start(ctx, "firestore.RepoLists")
+	defer span.End()
+
+	lists, err := readMeta(ctx, MetaRepos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return lists.List, lists.Inactive, nil
+}
@@ -0,0 +1,197 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	gfs "cloud.google.com/go/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Write is a single document write made through a Tx.
+// After is nil when the document is deleted.
+type Write struct {
+	Path   string
+	Before map[string]any
+	After  map[string]any
+}
+
+// Tx is a Firestore transaction working on raw documents.
+// Writes are buffered until the transaction function returns, so reads may follow writes,
+// and an audit entry is recorded for every write.
+type Tx struct {
+	client *gfs.Client
+	tx     *gfs.Transaction
+	reads  map[string]map[string]any
+	writes []Write
+}
+
+// RunTransaction runs fn in a Firestore transaction and returns the writes it made.
+// With dryrun set the transaction is read-only and the writes are returned without being applied.
+func RunTransaction(ctx context.Context, dryrun bool, fn func(*Tx) error) ([]Write, error) {
+	ctx, span := tracing.Start(ctx, "firestore.RunTransaction", attribute.Bool("dryrun", dryrun))
+	defer span.End()
+
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []gfs.TransactionOption
+	if dryrun {
+		opts = append(opts, gfs.ReadOnly)
+	} else if err := config.ConfirmWrite(); err != nil {
+		return nil, err
+	}
+
+	var writes []Write
+	var entries int
+	err = client.RunTransaction(ctx, func(ctx context.Context, tx *gfs.Transaction) error {
+		t := &Tx{client: client, tx: tx, reads: make(map[string]map[string]any)}
+		if err := fn(t); err != nil {
+			return err
+		}
+
+		writes = t.writes
+		if dryrun {
+			return nil
+		}
+
+		n, err := t.commit()
+		entries = n
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !dryrun {
+		metrics.Add(metrics.FirestoreWrites, float64(len(writes)+entries))
+	}
+
+	return writes, nil
+}
+
+// Get reads a document, returning nil if it does not exist.
+// Documents written earlier in the transaction are returned as written.
+func (t *Tx) Get(path string) (map[string]any, error) {
+	if data, ok := t.reads[path]; ok {
+		return data, nil
+	}
+
+	docsnap, err := t.tx.Get(t.client.Doc(path))
+	if status.Code(err) == codes.NotFound {
+		t.reads[path] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	metrics.Inc(metrics.FirestoreReads)
+
+	t.reads[path] = docsnap.Data()
+	return t.reads[path], nil
+}
+
+// Documents reads every document in a collection, keyed by document path
+func (t *Tx) Documents(collection string) (map[string]map[string]any, error) {
+	docsnaps, err := t.tx.Documents(t.client.Collection(collection)).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	metrics.Add(metrics.FirestoreReads, float64(len(docsnaps)))
+
+	docs := make(map[string]map[string]any, len(docsnaps))
+	for _, docsnap := range docsnaps {
+		path := documentPath(docsnap.Ref)
+		t.reads[path] = docsnap.Data()
+		docs[path] = t.reads[path]
+	}
+
+	return docs, nil
+}
+
+// Set replaces a document
+func (t *Tx) Set(path string, data map[string]any) error {
+	before, err := t.Get(path)
+	if err != nil {
+		return err
+	}
+
+	t.writes = append(t.writes, Write{Path: path, Before: before, After: data})
+	t.reads[path] = data
+	return nil
+}
+
+// Merge sets the given top-level fields of a document, leaving the others untouched
+func (t *Tx) Merge(path string, fields map[string]any) error {
+	before, err := t.Get(path)
+	if err != nil {
+		return err
+	}
+
+	after := make(map[string]any, len(before)+len(fields))
+	maps.Copy(after, before)
+	maps.Copy(after, fields)
+
+	return t.Set(path, after)
+}
+
+// Delete removes a document
+func (t *Tx) Delete(path string) error {
+	before, err := t.Get(path)
+	if err != nil {
+		return err
+	}
+
+	t.writes = append(t.writes, Write{Path: path, Before: before})
+	t.reads[path] = nil
+	return nil
+}
+
+// commit adds the buffered writes and their audit entries to the transaction and returns the number of audit entries
+func (t *Tx) commit() (int, error) {
+	entries := 0
+	for _, w := range t.writes {
+		ref := t.client.Doc(w.Path)
+		if ref == nil {
+			return 0, fmt.Errorf("%q is not a document path", w.Path)
+		}
+
+		logger.For(subsystem).Info(fmt.Sprintf("Writing %q", w.Path))
+		var after map[string]any
+		if w.After != nil {
+			resolved, err := resolveReferences(t.client, w.After)
+			if err != nil {
+				return 0, fmt.Errorf("%s: %w", w.Path, err)
+			}
+			after = resolved.(map[string]any)
+		}
+
+		var err error
+		if after == nil {
+			err = t.tx.Delete(ref)
+		} else {
+			err = t.tx.Set(ref, after)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if !audited(ref) {
+			continue
+		}
+		if err := recordAudit(t.client, t.tx, ref, w.Before, after); err != nil {
+			return 0, err
+		}
+		entries++
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,389 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"github.com/spf13/viper"
+)
+
+// subsystem names this package in log records
+const subsystem = "github"
+
+// Default endpoints, overridden by github.base_url and github.raw_url (e.g. for GitHub Enterprise or an httptest server)
+const (
+	DefaultBaseURL = "https://api.github.com/"
+	DefaultRawURL  = "https://raw.githubusercontent.com/"
+)
+
+const (
+	// defaultMaxRetries is how often a request is retried after a server error or secondary rate limit
+	defaultMaxRetries = 3
+	// defaultMinRemaining is the number of requests left in the rate limit window below which the client waits for the reset
+	defaultMinRemaining = 5
+	// defaultMaxWait is the longest the client waits for a rate limit to reset before giving up
+	defaultMaxWait = 15 * time.Minute
+)
+
+// Rate is the rate limit state reported by the last API response
+type Rate struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Error is returned for responses with a non-2xx status
+type Error struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+	}
+
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// IsNotFound reports whether err is a GitHub 404
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
+
+// Client talks to the GitHub REST API and raw content host
+type Client struct {
+	BaseURL *url.URL
+	RawBase *url.URL
+	HTTP    *http.Client
+	Token   string
+
+	MaxRetries   int
+	MinRemaining int
+	MaxWait      time.Duration
+
+	mu   sync.Mutex
+	rate Rate
+
+	// sleep waits for d or until ctx is done; tests may replace it to avoid real delays
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewClient returns a client for the given API base URL authenticating with token, which may be empty.
+// A nil httpClient uses a client with a 30 second timeout whose requests are traced.
+func NewClient(baseURL, rawURL, token string, httpClient *http.Client) (*Client, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second, Transport: tracing.Transport(http.DefaultTransport)}
+	}
+
+	base, err := parseBase(baseURL, DefaultBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := parseBase(rawURL, DefaultRawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		BaseURL:      base,
+		RawBase:      raw,
+		HTTP:         httpClient,
+		Token:        token,
+		MaxRetries:   defaultMaxRetries,
+		MinRemaining: defaultMinRemaining,
+		MaxWait:      defaultMaxWait,
+		sleep:        sleep,
+	}, nil
+}
+
+// New returns a client configured from github.token, github.base_url and github.raw_url
+func New() (*Client, error) {
+	return NewClient(viper.GetString("github.base_url"), viper.GetString("github.raw_url"), viper.GetString("github.token"), nil)
+}
+
+func parseBase(s, def string) (*url.URL, error) {
+	if s == "" {
+		s = def
+	}
+	if !strings.HasSuffix(s, "/") {
+		s += "/"
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub URL %q: %w", s, err)
+	}
+
+	return u, nil
+}
+
+// Rate returns the rate limit state from the most recent API response
+func (c *Client) Rate() Rate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rate
+}
+
+// NewRequest creates a request for path, which is resolved against the API base URL unless it is absolute
+func (c *Client) NewRequest(ctx context.Context, method, path string) (*http.Request, error) {
+	u, err := c.BaseURL.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == c.BaseURL.Host {
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	}
+
+	return req, nil
+}
+
+// Do sends a bodyless request, retrying server errors and secondary rate limits. When an earlier response left the
+// rate limit nearly used up, Do first waits for it to reset. A 2xx response body is decoded into v when v is not nil.
+// The caller must not read the returned response body, which has already been closed.
+func (c *Client) Do(req *http.Request, v any) (*http.Response, error) {
+	ctx := req.Context()
+
+	if err := c.waitForReset(ctx, req); err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(req)
+		if err != nil {
+			if attempt >= c.MaxRetries || ctx.Err() != nil {
+				return nil, err
+			}
+			if err := c.wait(ctx, backoff(attempt), "request failed: "+err.Error()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if delay, reason, retry := c.retryAfter(resp, attempt); retry && attempt < c.MaxRetries {
+			drain(resp)
+			if err := c.wait(ctx, delay, reason); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return resp, responseError(req, resp)
+		}
+
+		if v != nil && req.Method != http.MethodHead {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+				return resp, fmt.Errorf("%s %s: %w", req.Method, req.URL, err)
+			}
+		}
+
+		return resp, nil
+	}
+}
+
+// Get fetches path (or an absolute URL) and decodes the JSON response into v
+func (c *Client) Get(ctx context.Context, path string, v any) (*http.Response, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req, v)
+}
+
+// GetAll fetches every page of a list endpoint, following the Link header, and returns the combined items
+func GetAll[T any](ctx context.Context, c *Client, path string) ([]T, error) {
+	var all []T
+
+	for path != "" {
+		var page []T
+		resp, err := c.Get(ctx, path, &page)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, page...)
+		path = NextPage(resp)
+	}
+
+	return all, nil
+}
+
+// NextPage returns the URL of the next page from a response's Link header, or "" on the last page
+func NextPage(resp *http.Response) string {
+	for link := range strings.SplitSeq(resp.Header.Get("Link"), ",") {
+		target, params, ok := strings.Cut(link, ";")
+		if ok && strings.Contains(params, `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>")
+		}
+	}
+
+	return ""
+}
+
+// send performs a single attempt, adding authentication for GitHub hosts and recording the rate limit state
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if c.Token != "" && c.isGitHub(req) {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", "pdt")
+	}
+
+	start := time.Now()
+	resp, err := c.HTTP.Do(req)
+	metrics.Since(metrics.FetchDuration, start, "method", req.Method)
+	if err != nil {
+		metrics.Error(subsystem, err)
+		return nil, err
+	}
+
+	if c.isAPI(req) {
+		// A response without rate limit headers leaves the state unknown rather than stale
+		rate, _ := parseRate(resp.Header)
+		c.mu.Lock()
+		c.rate = rate
+		c.mu.Unlock()
+	}
+	logger.For(subsystem).Debug(fmt.Sprintf("%s %s: %s (rate limit remaining: %d)", req.Method, req.URL, resp.Status, c.Rate().Remaining))
+
+	return resp, nil
+}
+
+// retryAfter decides whether a response should be retried and how long to wait first
+func (c *Client) retryAfter(resp *http.Response, attempt int) (time.Duration, string, bool) {
+	switch {
+	case resp.StatusCode >= 500:
+		return backoff(attempt), resp.Status, true
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		// Secondary rate limits say how long to wait; primary ones reset at a fixed time
+		if s := resp.Header.Get("Retry-After"); s != "" {
+			if seconds, err := strconv.Atoi(s); err == nil {
+				return time.Duration(seconds) * time.Second, "secondary rate limit", true
+			}
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if rate, ok := parseRate(resp.Header); ok {
+				return time.Until(rate.Reset), "rate limit exceeded", true
+			}
+		}
+	}
+
+	return 0, "", false
+}
+
+// waitForReset stays clear of the primary rate limit instead of running into it. The wait happens before the next
+// API request rather than after the response that used up the limit, so that response is never lost to a timeout
+// or a wait that is too long.
+func (c *Client) waitForReset(ctx context.Context, req *http.Request) error {
+	if !c.isAPI(req) {
+		return nil
+	}
+
+	rate := c.Rate()
+	if rate.Limit == 0 || rate.Remaining >= c.MinRemaining || !time.Now().Before(rate.Reset) {
+		return nil
+	}
+	if err := c.wait(ctx, time.Until(rate.Reset), fmt.Sprintf("only %d API requests left", rate.Remaining)); err != nil {
+		return err
+	}
+
+	// The next response reports the new window
+	c.mu.Lock()
+	c.rate = Rate{}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// wait sleeps before a retry, using exponential backoff when no delay is known
+func (c *Client) wait(ctx context.Context, d time.Duration, reason string) error {
+	if d <= 0 {
+		d = backoff(0)
+	}
+	if d > c.MaxWait {
+		return fmt.Errorf("GitHub %s; giving up instead of waiting %s", reason, d.Round(time.Second))
+	}
+
+	logger.For(subsystem).Warn(fmt.Sprintf("GitHub %s; waiting %s", reason, d.Round(time.Second)))
+	return c.sleep(ctx, d)
+}
+
+func (c *Client) isAPI(req *http.Request) bool {
+	return req.URL.Host == c.BaseURL.Host
+}
+
+// isGitHub reports whether the token may be sent with req; it is never sent to third-party hosts
+func (c *Client) isGitHub(req *http.Request) bool {
+	return req.URL.Host == c.BaseURL.Host || req.URL.Host == c.RawBase.Host
+}
+
+func parseRate(h http.Header) (Rate, bool) {
+	limit, err1 := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	remaining, err2 := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	reset, err3 := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return Rate{}, false
+	}
+
+	return Rate{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}, true
+}
+
+func responseError(req *http.Request, resp *http.Response) error {
+	e := &Error{StatusCode: resp.StatusCode, Method: req.Method, URL: req.URL.String()}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if req.Method != http.MethodHead && json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&body) == nil {
+		e.Message = body.Message
+	}
+	metrics.Inc(metrics.Errors, "subsystem", subsystem, "class", "http_"+strconv.Itoa(resp.StatusCode))
+
+	return e
+}
+
+func backoff(attempt int) time.Duration {
+	return time.Second << attempt
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
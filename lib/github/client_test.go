@@ -0,0 +1,312 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client for server whose waits are recorded instead of slept
+func newTestClient(t *testing.T, server *httptest.Server) (*Client, *[]time.Duration) {
+	t.Helper()
+
+	c, err := NewClient(server.URL, server.URL, "secret", server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var waits []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+
+	return c, &waits
+}
+
+// sequence answers the n-th request with the n-th handler, repeating the last one
+func sequence(calls *atomic.Int32, handlers ...http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		handlers[min(n, len(handlers)-1)](w, r)
+	}
+}
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}
+}
+
+func ok(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func rateHeaders(w http.ResponseWriter, remaining int, reset time.Time) {
+	w.Header().Set("X-RateLimit-Limit", "5000")
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+}
+
+func TestDoRetriesServerErrorsWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(sequence(&calls, status(http.StatusBadGateway), status(http.StatusServiceUnavailable), ok(`{"full_name": "owner/repo"}`)))
+	defer server.Close()
+
+	c, waits := newTestClient(t, server)
+
+	var repo struct {
+		FullName string `json:"full_name"`
+	}
+	if _, err := c.Get(context.Background(), "repos/owner/repo", &repo); err != nil {
+		t.Fatal(err)
+	}
+
+	if repo.FullName != "owner/repo" {
+		t.Errorf("full_name = %q, want owner/repo", repo.FullName)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("made %d requests, want 3", got)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !slices.Equal(*waits, want) {
+		t.Errorf("waits = %v, want %v", *waits, want)
+	}
+}
+
+func TestDoGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(sequence(&calls, status(http.StatusInternalServerError)))
+	defer server.Close()
+
+	c, waits := newTestClient(t, server)
+	c.MaxRetries = 2
+
+	_, err := c.Get(context.Background(), "repos/owner/repo", nil)
+
+	var ghErr *Error
+	if !errors.As(err, &ghErr) || ghErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want a 500 *Error", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("made %d requests, want 3", got)
+	}
+	if len(*waits) != 2 {
+		t.Errorf("waited %d times, want 2", len(*waits))
+	}
+}
+
+func TestDoDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(sequence(&calls, status(http.StatusNotFound)))
+	defer server.Close()
+
+	c, waits := newTestClient(t, server)
+
+	_, err := c.Get(context.Background(), "repos/owner/missing", nil)
+	if !IsNotFound(err) {
+		t.Fatalf("err = %v, want a 404", err)
+	}
+	if got := calls.Load(); got != 1 || len(*waits) != 0 {
+		t.Errorf("made %d requests and %d waits, want 1 and 0", got, len(*waits))
+	}
+}
+
+func TestDoWaitsForSecondaryRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	limited := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusForbidden)
+	}
+	server := httptest.NewServer(sequence(&calls, limited, ok(`{}`)))
+	defer server.Close()
+
+	c, waits := newTestClient(t, server)
+
+	if _, err := c.Get(context.Background(), "search/repositories", nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := []time.Duration{7 * time.Second}; !slices.Equal(*waits, want) {
+		t.Errorf("waits = %v, want %v", *waits, want)
+	}
+}
+
+func TestDoWaitsForPrimaryRateLimitReset(t *testing.T) {
+	var calls atomic.Int32
+	reset := time.Now().Add(time.Minute)
+	exceeded := func(w http.ResponseWriter, r *http.Request) {
+		rateHeaders(w, 0, reset)
+		w.WriteHeader(http.StatusForbidden)
+	}
+	server := httptest.NewServer(sequence(&calls, exceeded, ok(`{}`)))
+	defer server.Close()
+
+	c, waits := newTestClient(t, server)
+
+	if _, err := c.Get(context.Background(), "repos/owner/repo", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*waits) != 1 || (*waits)[0] < 58*time.Second || (*waits)[0] > time.Minute {
+		t.Errorf("waits = %v, want one wait of about a minute", *waits)
+	}
+}
+
+func TestDoWaitsBeforeTheNextRequestWhenRateLimitIsNearlyUsedUp(t *testing.T) {
+	var calls atomic.Int32
+	reset := time.Now().Add(30 * time.Second)
+	low := func(w http.ResponseWriter, r *http.Request) {
+		rateHeaders(w, 2, reset)
+		ok(`{}`)(w, r)
+	}
+	fresh := func(w http.ResponseWriter, r *http.Request) {
+		rateHeaders(w, 4999, reset.Add(time.Hour))
+		ok(`{}`)(w, r)
+	}
+	server := httptest.NewServer(sequence(&calls, low, fresh))
+	defer server.Close()
+
+	c, waits := newTestClient(t, server)
+
+	if _, err := c.Get(context.Background(), "repos/owner/repo", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Rate(); got.Remaining != 2 || got.Limit != 5000 {
+		t.Errorf("Rate() = %+v, want 2 of 5000 remaining", got)
+	}
+	if len(*waits) != 0 {
+		t.Fatalf("waits = %v after the first request, want none", *waits)
+	}
+
+	if _, err := c.Get(context.Background(), "repos/owner/other", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*waits) != 1 || (*waits)[0] < 28*time.Second || (*waits)[0] > 30*time.Second {
+		t.Errorf("waits = %v, want one wait until the reset", *waits)
+	}
+	if got := c.Rate(); got.Remaining != 4999 {
+		t.Errorf("Rate() = %+v, want the new window", got)
+	}
+}
+
+func TestDoKeepsTheResponseThatUsedUpTheRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	low := func(w http.ResponseWriter, r *http.Request) {
+		rateHeaders(w, 0, time.Now().Add(time.Hour))
+		ok(`{"full_name": "owner/repo"}`)(w, r)
+	}
+	server := httptest.NewServer(sequence(&calls, low))
+	defer server.Close()
+
+	c, waits := newTestClient(t, server)
+	c.MaxWait = time.Minute
+
+	var repo struct {
+		FullName string `json:"full_name"`
+	}
+	if _, err := c.Get(context.Background(), "repos/owner/repo", &repo); err != nil || repo.FullName != "owner/repo" {
+		t.Fatalf("Get() = %q, %v; want owner/repo, nil", repo.FullName, err)
+	}
+
+	// The reset is further away than MaxWait, so the next request gives up without being sent
+	if _, err := c.Get(context.Background(), "repos/owner/other", nil); err == nil {
+		t.Fatal("second Get succeeded, want an error")
+	}
+	if got := calls.Load(); got != 1 || len(*waits) != 0 {
+		t.Errorf("made %d requests and %d waits, want 1 and 0", got, len(*waits))
+	}
+}
+
+func TestDoWaitLongerThanClientTimeout(t *testing.T) {
+	// Large enough that the body can't be fully buffered along with the headers
+	name := strings.Repeat("x", 1<<20)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rateHeaders(w, 1, time.Now().Add(time.Minute))
+		ok(`{"full_name": "` + name + `"}`)(w, r)
+	}))
+	defer server.Close()
+
+	httpClient := server.Client()
+	httpClient.Timeout = 200 * time.Millisecond
+	c, err := NewClient(server.URL, server.URL, "", httpClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The wait outlasts the client timeout, which also covers reading the body
+	waits := 0
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits++
+		time.Sleep(2 * httpClient.Timeout)
+		return nil
+	}
+
+	for range 2 {
+		var repo struct {
+			FullName string `json:"full_name"`
+		}
+		if _, err := c.Get(context.Background(), "repos/owner/repo", &repo); err != nil {
+			t.Fatal(err)
+		}
+		if repo.FullName != name {
+			t.Errorf("full_name has %d bytes, want %d", len(repo.FullName), len(name))
+		}
+	}
+	if waits != 1 {
+		t.Errorf("waited %d times, want once before the second request", waits)
+	}
+}
+
+func TestDoGivesUpOnWaitsLongerThanMaxWait(t *testing.T) {
+	var calls atomic.Int32
+	exceeded := func(w http.ResponseWriter, r *http.Request) {
+		rateHeaders(w, 0, time.Now().Add(time.Hour))
+		w.WriteHeader(http.StatusForbidden)
+	}
+	server := httptest.NewServer(sequence(&calls, exceeded))
+	defer server.Close()
+
+	c, waits := newTestClient(t, server)
+	c.MaxWait = time.Minute
+
+	if _, err := c.Get(context.Background(), "repos/owner/repo", nil); err == nil {
+		t.Fatal("Get succeeded, want an error")
+	}
+	if got := calls.Load(); got != 1 || len(*waits) != 0 {
+		t.Errorf("made %d requests and %d waits, want 1 and 0", got, len(*waits))
+	}
+}
+
+func TestSendOnlyAuthenticatesGitHubHosts(t *testing.T) {
+	var auth atomic.Value
+	record := func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+	}
+	github := httptest.NewServer(http.HandlerFunc(record))
+	defer github.Close()
+	other := httptest.NewServer(http.HandlerFunc(record))
+	defer other.Close()
+
+	c, _ := newTestClient(t, github)
+
+	if _, err := c.Get(context.Background(), "user", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := auth.Load(); got != "Bearer secret" {
+		t.Errorf("Authorization to GitHub = %q, want the token", got)
+	}
+
+	if _, err := c.Get(context.Background(), other.URL+"/file.zip", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := auth.Load(); got != "" {
+		t.Errorf("Authorization to a third-party host = %q, want none", got)
+	}
+}
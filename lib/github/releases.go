@@ -0,0 +1,45 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Release is the subset of the GitHub release resource pdt uses
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name               string `json:"name"`
+	ContentType        string `json:"content_type"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Release fetches a release of repo by tag, or the newest published release when tag is "latest"
+func (c *Client) Release(ctx context.Context, repo, tag string) (*Release, error) {
+	name, err := RepoName(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	path := "repos/" + name + "/releases/latest"
+	if tag != "latest" {
+		path = "repos/" + name + "/releases/tags/" + url.PathEscape(tag)
+	}
+
+	var r Release
+	if _, err := c.Get(ctx, path, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
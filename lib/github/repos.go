@@ -0,0 +1,116 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Repository is the subset of the GitHub repository resource pdt uses
+type Repository struct {
+	FullName        string    `json:"full_name"`
+	HTMLURL         string    `json:"html_url"`
+	Description     string    `json:"description"`
+	DefaultBranch   string    `json:"default_branch"`
+	Archived        bool      `json:"archived"`
+	Disabled        bool      `json:"disabled"`
+	StargazersCount int       `json:"stargazers_count"`
+	PushedAt        time.Time `json:"pushed_at"`
+	Topics          []string  `json:"topics"`
+}
+
+// User is the subset of the GitHub user resource pdt uses
+type User struct {
+	Login string `json:"login"`
+}
+
+// RepoName normalizes a repository reference ("owner/name" or a GitHub URL) to "owner/name"
+func RepoName(repo string) (string, error) {
+	name := strings.TrimSpace(repo)
+	name = strings.TrimPrefix(name, "https://")
+	name = strings.TrimPrefix(name, "http://")
+	name = strings.TrimPrefix(name, "github.com/")
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+
+	owner, project, ok := strings.Cut(name, "/")
+	if !ok || owner == "" || project == "" || strings.Contains(project, "/") {
+		return "", fmt.Errorf("%q is not a GitHub repository (expected owner/name)", repo)
+	}
+
+	return name, nil
+}
+
+// RawURL returns the URL of a file on the default branch of a GitHub repository
+func (c *Client) RawURL(repo, file string) (string, error) {
+	name, err := RepoName(repo)
+	if err != nil {
+		return "", err
+	}
+
+	return c.RawBase.JoinPath(name, "HEAD", file).String(), nil
+}
+
+// Repository fetches a repository by name
+func (c *Client) Repository(ctx context.Context, repo string) (*Repository, error) {
+	name, err := RepoName(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Repository
+	if _, err := c.Get(ctx, "repos/"+name, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Exists reports whether url (usually a raw file URL) can be fetched
+func (c *Client) Exists(ctx context.Context, url string) (bool, error) {
+	req, err := c.NewRequest(ctx, http.MethodHead, url)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := c.Do(req, nil); err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CurrentUser returns the user the token belongs to
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	var u User
+	if _, err := c.Get(ctx, "user", &u); err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// RepoOf returns the repository a raw or github.com file URL belongs to, if it belongs to one
+func (c *Client) RepoOf(fileURL string) (string, bool) {
+	u, err := url.Parse(fileURL)
+	if err != nil || (u.Host != c.RawBase.Host && u.Host != "github.com") {
+		return "", false
+	}
+
+	rel := strings.TrimPrefix(u.Path, c.RawBase.Path)
+	if u.Host == "github.com" {
+		rel = strings.TrimPrefix(u.Path, "/")
+	}
+	segments := strings.SplitN(rel, "/", 3)
+	if len(segments) < 2 {
+		return "", false
+	}
+
+	name, err := RepoName(segments[0] + "/" + segments[1])
+	return name, err == nil
+}
@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// searchPageSize is the largest page the search API returns
+const searchPageSize = 100
+
+// SearchRepositories returns up to limit repositories matching a search query (e.g. "topic:icarus-mod")
+func (c *Client) SearchRepositories(ctx context.Context, query string, limit int) ([]Repository, error) {
+	var repos []Repository
+
+	path := "search/repositories?" + searchParams(query, limit).Encode()
+	for path != "" && len(repos) < limit {
+		var page struct {
+			Items []Repository `json:"items"`
+		}
+		resp, err := c.Get(ctx, path, &page)
+		if err != nil {
+			return repos, err
+		}
+		repos = append(repos, page.Items...)
+		path = NextPage(resp)
+	}
+
+	return repos[:min(len(repos), limit)], nil
+}
+
+// SearchCode returns the repositories of up to limit files matching a code search query (e.g. "filename:modinfo.json").
+// GitHub only allows code search with a token.
+func (c *Client) SearchCode(ctx context.Context, query string, limit int) ([]Repository, error) {
+	var repos []Repository
+
+	path := "search/code?" + searchParams(query, limit).Encode()
+	for path != "" && len(repos) < limit {
+		var page struct {
+			Items []struct {
+				Repository Repository `json:"repository"`
+			} `json:"items"`
+		}
+		resp, err := c.Get(ctx, path, &page)
+		if err != nil {
+			return repos, err
+		}
+		for _, item := range page.Items {
+			repos = append(repos, item.Repository)
+		}
+		path = NextPage(resp)
+	}
+
+	return repos[:min(len(repos), limit)], nil
+}
+
+func searchParams(query string, limit int) url.Values {
+	return url.Values{
+		"q":        {query},
+		"per_page": {strconv.Itoa(min(limit, searchPageSize))},
+	}
+}
@@ -0,0 +1,105 @@
+package linkcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache keeps link check results on disk so repeated runs within the TTL skip the network.
+// A nil *Cache is valid and caches nothing.
+type Cache struct {
+	file string
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	results map[string]Result
+}
+
+// DefaultCacheFile returns the cache location under the user's cache directory
+func DefaultCacheFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "pdt", "linkcheck.json"), nil
+}
+
+// OpenCache loads the cache from file; a missing file gives an empty cache
+func OpenCache(file string, ttl time.Duration) (*Cache, error) {
+	c := &Cache{file: file, ttl: ttl, results: make(map[string]Result)}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.results); err != nil {
+		// A corrupt cache only costs a re-check
+		c.results = make(map[string]Result)
+	}
+
+	return c, nil
+}
+
+// Get returns the cached result for a URL if it was checked within the TTL
+func (c *Cache) Get(url string) (Result, bool) {
+	if c == nil {
+		return Result{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.results[url]
+	if !ok || time.Since(r.CheckedAt) > c.ttl {
+		return Result{}, false
+	}
+
+	return r, true
+}
+
+// Put stores a result
+func (c *Cache) Put(r Result) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results[r.URL] = r
+}
+
+// Save writes the unexpired results back to the cache file
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for url, r := range c.results {
+		if time.Since(r.CheckedAt) > c.ttl {
+			delete(c.results, url)
+		}
+	}
+
+	data, err := json.Marshal(c.results)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.file), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.file, data, 0o644)
+}
@@ -0,0 +1,280 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// subsystem names this package in log records
+const subsystem = "linkcheck"
+
+// Options controls how links are checked
+type Options struct {
+	// Concurrency is the number of links checked at once
+	Concurrency int
+	// PerHost is the number of links checked at once on any single host
+	PerHost int
+	// MaxRedirects is the number of redirects followed before a link counts as broken
+	MaxRedirects int
+	// Timeout bounds each request
+	Timeout time.Duration
+	// Cache, when not nil, supplies results checked within its TTL and stores new ones
+	Cache *Cache
+}
+
+// Result is the outcome of checking one URL
+type Result struct {
+	URL        string `json:"url"`
+	Method     string `json:"method"`
+	StatusCode int    `json:"status_code,omitempty"`
+	FinalURL   string `json:"final_url,omitempty"`
+	Permanent  bool   `json:"permanent_redirect,omitempty"`
+	// ContentLength is the size of the resource in bytes, or nil if the server did not report one
+	ContentLength *int64        `json:"content_length,omitempty"`
+	ContentType   string        `json:"content_type,omitempty"`
+	Duration      time.Duration `json:"duration"`
+	Error         string        `json:"error,omitempty"`
+	CheckedAt     time.Time     `json:"checked_at"`
+}
+
+// Broken reports whether the URL could not be fetched
+func (r Result) Broken() bool {
+	return r.Error != "" || r.StatusCode < 200 || r.StatusCode > 299
+}
+
+// Redirected reports whether the URL permanently redirects elsewhere and should be updated.
+// Temporary redirects (e.g. release downloads handed off to a CDN) are expected and not reported.
+func (r Result) Redirected() bool {
+	return r.Permanent && r.FinalURL != "" && r.FinalURL != r.URL
+}
+
+// Problem describes what is wrong with the URL, or "" if nothing is
+func (r Result) Problem(slow time.Duration) string {
+	switch {
+	case r.Error != "":
+		return "broken: " + r.Error
+	case r.Broken():
+		return fmt.Sprintf("broken: %d %s", r.StatusCode, http.StatusText(r.StatusCode))
+	case r.Redirected():
+		return "redirected to " + r.FinalURL
+	case slow > 0 && r.Duration > slow:
+		return fmt.Sprintf("slow: %s", r.Duration.Round(time.Millisecond))
+	}
+
+	return ""
+}
+
+// Checker checks URLs with per-host concurrency limits
+type Checker struct {
+	opts   Options
+	client *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+// redirectsKey carries the redirect state of a request through the client's CheckRedirect hook
+type redirectsKey struct{}
+
+type redirects struct {
+	permanent bool
+}
+
+// New returns a checker using opts, filling in defaults for unset fields
+func New(opts Options) *Checker {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 16
+	}
+	if opts.PerHost <= 0 {
+		opts.PerHost = 4
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = 5
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 15 * time.Second
+	}
+
+	c := &Checker{opts: opts, hosts: make(map[string]chan struct{})}
+	c.client = &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: tracing.Transport(http.DefaultTransport),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > opts.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", opts.MaxRedirects)
+			}
+			if r, ok := req.Context().Value(redirectsKey{}).(*redirects); ok && req.Response != nil {
+				code := req.Response.StatusCode
+				r.permanent = r.permanent || code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect
+			}
+			return nil
+		},
+	}
+
+	return c
+}
+
+// CheckAll checks every distinct URL and returns the results keyed by URL
+func (c *Checker) CheckAll(ctx context.Context, urls []string) map[string]Result {
+	results := make(map[string]Result, len(urls))
+	var mu sync.Mutex
+
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for range c.opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range queue {
+				r := c.Check(ctx, u)
+				mu.Lock()
+				results[u] = r
+				mu.Unlock()
+			}
+		}()
+	}
+
+	seen := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		if !seen[u] {
+			seen[u] = true
+			queue <- u
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// Check checks a single URL with HEAD, falling back to a ranged GET for servers that reject HEAD
+func (c *Checker) Check(ctx context.Context, rawURL string) Result {
+	if r, ok := c.opts.Cache.Get(rawURL); ok {
+		return r
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return c.store(Result{URL: rawURL, Error: "invalid URL", CheckedAt: time.Now().UTC()})
+	}
+
+	release := c.acquire(u.Host)
+	defer release()
+
+	r := c.request(ctx, http.MethodHead, rawURL)
+	if r.Broken() {
+		r = c.request(ctx, http.MethodGet, rawURL)
+	}
+
+	outcome := "ok"
+	if r.Broken() {
+		outcome = "broken"
+	} else if r.Redirected() {
+		outcome = "redirected"
+	}
+	metrics.Inc(metrics.LinkChecks, "result", outcome)
+	metrics.Observe(metrics.LinkCheckDuration, r.Duration.Seconds())
+
+	return c.store(r)
+}
+
+func (c *Checker) request(ctx context.Context, method, rawURL string) (r Result) {
+	ctx, span := tracing.Start(ctx, "linkcheck.request", attribute.String("url", rawURL), attribute.String("method", method))
+	defer func() {
+		span.SetAttributes(attribute.Int("status", r.StatusCode))
+		span.End()
+	}()
+
+	r = Result{URL: rawURL, Method: method, CheckedAt: time.Now().UTC()}
+
+	state := &redirects{}
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, redirectsKey{}, state), method, rawURL, nil)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	req.Header.Set("User-Agent", "pdt")
+	if method == http.MethodGet {
+		// Only the first byte is needed to know the file is there
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	r.Duration = time.Since(start)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		r.Error = err.Error()
+		logger.For(subsystem).Debug(fmt.Sprintf("%s %s: %v", method, rawURL, err))
+		return r
+	}
+	resp.Body.Close()
+
+	// A ranged GET answers 206 Partial Content
+	r.StatusCode = resp.StatusCode
+	if r.StatusCode == http.StatusPartialContent {
+		r.StatusCode = http.StatusOK
+	}
+	r.FinalURL = resp.Request.URL.String()
+	r.Permanent = state.permanent
+	r.ContentLength = contentLength(resp)
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		r.ContentType = strings.ToLower(mediaType)
+	}
+	logger.For(subsystem).Debug(fmt.Sprintf("%s %s: %s in %s", method, rawURL, resp.Status, r.Duration.Round(time.Millisecond)))
+
+	return r
+}
+
+// contentLength returns the full size of the resource behind resp, taken from
+// Content-Range for a ranged response, or nil if it is unknown
+func contentLength(resp *http.Response) *int64 {
+	if resp.StatusCode == http.StatusPartialContent {
+		// Content-Range: bytes 0-0/12345
+		_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+		if n, err := strconv.ParseInt(total, 10, 64); ok && err == nil {
+			return &n
+		}
+		return nil
+	}
+	if resp.ContentLength < 0 {
+		return nil
+	}
+
+	return &resp.ContentLength
+}
+
+// acquire waits for a free slot on host and returns the function that releases it
+func (c *Checker) acquire(host string) func() {
+	c.mu.Lock()
+	sem, ok := c.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, c.opts.PerHost)
+		c.hosts[host] = sem
+	}
+	c.mu.Unlock()
+
+	sem <- struct{}{}
+	return func() { <-sem }
+}
+
+func (c *Checker) store(r Result) Result {
+	c.opts.Cache.Put(r)
+	return r
+}
@@ -0,0 +1,218 @@
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rejectHead answers HEAD with status and a ranged GET with the first byte of a 12345 byte zip file
+func rejectHead(status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(status)
+			return
+		}
+		if r.Header.Get("Range") != "bytes=0-0" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Range", "bytes 0-0/12345")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte("P"))
+	}
+}
+
+func TestCheckFallsBackToRangedGet(t *testing.T) {
+	for _, status := range []int{http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			server := httptest.NewServer(rejectHead(status))
+			defer server.Close()
+
+			r := New(Options{}).Check(context.Background(), server.URL+"/mod.zip")
+
+			if r.Broken() || r.Method != http.MethodGet || r.StatusCode != http.StatusOK {
+				t.Fatalf("Check() = %+v, want a working ranged GET", r)
+			}
+			if r.ContentLength == nil || *r.ContentLength != 12345 {
+				t.Errorf("ContentLength = %v, want 12345 from Content-Range", r.ContentLength)
+			}
+			if r.ContentType != "application/zip" {
+				t.Errorf("ContentType = %q, want application/zip", r.ContentType)
+			}
+		})
+	}
+}
+
+func TestCheckUsesHeadWhenSupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected %s request", r.Method)
+		}
+		w.Header().Set("Content-Length", "42")
+	}))
+	defer server.Close()
+
+	r := New(Options{}).Check(context.Background(), server.URL)
+	if r.Broken() || r.Method != http.MethodHead {
+		t.Fatalf("Check() = %+v, want a working HEAD", r)
+	}
+	if r.ContentLength == nil || *r.ContentLength != 42 {
+		t.Errorf("ContentLength = %v, want 42", r.ContentLength)
+	}
+}
+
+func TestCheckRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/loop/{n}", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.PathValue("n"))
+		http.Redirect(w, r, fmt.Sprintf("/loop/%d", n+1), http.StatusFound)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/temporary", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	checker := New(Options{MaxRedirects: 2})
+	ctx := context.Background()
+
+	if r := checker.Check(ctx, server.URL+"/loop/0"); !r.Broken() || !strings.Contains(r.Error, "stopped after 2 redirects") {
+		t.Errorf("redirect loop: Check() = %+v, want broken after 2 redirects", r)
+	}
+
+	r := checker.Check(ctx, server.URL+"/moved")
+	if r.Broken() || !r.Redirected() || r.FinalURL != server.URL+"/final" {
+		t.Errorf("permanent redirect: Check() = %+v, want redirected to /final", r)
+	}
+	if want := "redirected to " + server.URL + "/final"; r.Problem(0) != want {
+		t.Errorf("Problem() = %q, want %q", r.Problem(0), want)
+	}
+
+	if r := checker.Check(ctx, server.URL+"/temporary"); r.Broken() || r.Redirected() {
+		t.Errorf("temporary redirect: Check() = %+v, want a working link that is not reported", r)
+	}
+}
+
+func TestContentLength(t *testing.T) {
+	size := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		status        int
+		contentRange  string
+		contentLength int64
+		want          *int64
+	}{
+		{http.StatusPartialContent, "bytes 0-0/12345", 1, size(12345)},
+		{http.StatusPartialContent, "bytes 0-0/*", 1, nil},
+		{http.StatusPartialContent, "", 1, nil},
+		{http.StatusOK, "", 2048, size(2048)},
+		{http.StatusOK, "", 0, size(0)},
+		{http.StatusOK, "", -1, nil},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, ContentLength: tt.contentLength, Header: http.Header{}}
+		if tt.contentRange != "" {
+			resp.Header.Set("Content-Range", tt.contentRange)
+		}
+
+		got := contentLength(resp)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("contentLength(%d, %q, %d) = %v, want %v", tt.status, tt.contentRange, tt.contentLength, got, tt.want)
+		}
+	}
+}
+
+func TestCacheExpiresResults(t *testing.T) {
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "linkcheck.json"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Put(Result{URL: "https://example.com/fresh", StatusCode: 200, CheckedAt: time.Now().Add(-time.Minute)})
+	cache.Put(Result{URL: "https://example.com/stale", StatusCode: 200, CheckedAt: time.Now().Add(-2 * time.Hour)})
+
+	if _, ok := cache.Get("https://example.com/fresh"); !ok {
+		t.Error("result checked a minute ago is not cached")
+	}
+	if _, ok := cache.Get("https://example.com/stale"); ok {
+		t.Error("result checked two hours ago is still cached")
+	}
+	if _, ok := cache.Get("https://example.com/unknown"); ok {
+		t.Error("unknown URL is cached")
+	}
+}
+
+func TestCachePersistsUnexpiredResults(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "pdt", "linkcheck.json")
+
+	cache, err := OpenCache(file, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Put(Result{URL: "https://example.com/fresh", StatusCode: 404, CheckedAt: time.Now()})
+	cache.Put(Result{URL: "https://example.com/stale", StatusCode: 200, CheckedAt: time.Now().Add(-2 * time.Hour)})
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenCache(file, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := reopened.Get("https://example.com/fresh"); !ok || r.StatusCode != 404 {
+		t.Errorf("Get(fresh) = %+v, %t; want the saved 404", r, ok)
+	}
+	if len(reopened.results) != 1 {
+		t.Errorf("saved %d results, want only the unexpired one", len(reopened.results))
+	}
+}
+
+func TestCacheServesCheckerWithoutNetwork(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requests++ }))
+	defer server.Close()
+
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "linkcheck.json"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := New(Options{Cache: cache})
+
+	first := checker.Check(context.Background(), server.URL)
+	second := checker.Check(context.Background(), server.URL)
+	if requests != 1 || first.CheckedAt != second.CheckedAt {
+		t.Errorf("made %d requests, want the second check served from the cache", requests)
+	}
+}
+
+func TestOpenCacheIgnoresCorruptFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "linkcheck.json")
+	if err := os.WriteFile(file, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := OpenCache(file, time.Hour)
+	if err != nil || len(cache.results) != 0 {
+		t.Errorf("OpenCache() = %v, %v; want an empty cache", cache, err)
+	}
+
+	var none *Cache
+	none.Put(Result{URL: "https://example.com"})
+	if _, ok := none.Get("https://example.com"); ok || none.Save() != nil {
+		t.Error("a nil cache must cache nothing")
+	}
+}
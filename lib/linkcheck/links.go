@@ -0,0 +1,67 @@
+package linkcheck
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+)
+
+// Link is a URL referenced by a mod or tool document
+type Link struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Author string `json:"author"`
+	Name   string `json:"name"`
+	// Field is the dotted path of the URL in the document (e.g. "files.pak" or "imageURL")
+	Field string `json:"field"`
+	URL   string `json:"url"`
+}
+
+// Links returns every file, image and readme URL across the mods and tools collections
+func Links(ctx context.Context) ([]Link, error) {
+	var links []Link
+
+	for _, kind := range models.Kinds {
+		entries, err := firestore.Entries(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			var author, name, imageURL, readmeURL string
+			var files map[string]string
+			switch doc := e.Doc.(type) {
+			case *models.Mod:
+				author, name, files, imageURL, readmeURL = doc.Author, doc.Name, doc.Files, doc.ImageURL, doc.ReadmeURL
+			case *models.Tool:
+				author, name, files, imageURL, readmeURL = doc.Author, doc.Name, doc.Files, doc.ImageURL, doc.ReadmeURL
+			}
+
+			add := func(field, url string) {
+				if url != "" {
+					links = append(links, Link{Kind: kind, ID: e.ID, Author: author, Name: name, Field: field, URL: url})
+				}
+			}
+			for _, fileType := range slices.Sorted(maps.Keys(files)) {
+				add("files."+fileType, files[fileType])
+			}
+			add("imageURL", imageURL)
+			add("readmeURL", readmeURL)
+		}
+	}
+
+	return links, nil
+}
+
+// URLs returns the URLs of links
+func URLs(links []Link) []string {
+	urls := make([]string, len(links))
+	for i, l := range links {
+		urls[i] = l.URL
+	}
+
+	return urls
+}
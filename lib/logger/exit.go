@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+)
+
+var exitHooks []func(error)
+
+// AtExit registers fn to run when CheckErr is about to exit, e.g. to flush metrics or traces
+func AtExit(fn func(error)) {
+	exitHooks = append(exitHooks, fn)
+}
+
+// CheckErr prints a redacted error and exits with a non-zero status if err is not nil.
+// Commands use it in place of cobra.CheckErr so secrets never reach the terminal.
+func CheckErr(err error) {
+	if err == nil {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "Error:", Redact(err.Error()))
+	for _, fn := range exitHooks {
+		fn(err)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+// Levels holds the default log level and any per-subsystem overrides
+type Levels struct {
+	Default    slog.Level
+	Subsystems map[string]slog.Level
+}
+
+// Level returns the level for a subsystem, falling back to the default
+func (l Levels) Level(subsystem string) slog.Level {
+	if level, ok := l.Subsystems[subsystem]; ok {
+		return level
+	}
+
+	return l.Default
+}
+
+// Min returns the most verbose level in use, which the underlying handler must allow through
+func (l Levels) Min() slog.Level {
+	level := l.Default
+	for _, sub := range l.Subsystems {
+		level = min(level, sub)
+	}
+
+	return level
+}
+
+// ParseLevels parses a level spec such as "firestore=debug,github=info,sync=warn" on top of the default level.
+// An entry without a subsystem (e.g. "info") replaces the default level.
+func ParseLevels(spec string, def slog.Level) (Levels, error) {
+	levels := Levels{Default: def, Subsystems: map[string]slog.Level{}}
+
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		subsystem, name, found := strings.Cut(entry, "=")
+		if !found {
+			subsystem, name = "", entry
+		}
+
+		level, err := parseLevel(name)
+		if err != nil {
+			return levels, fmt.Errorf("invalid log level %q: %w", entry, err)
+		}
+
+		if subsystem = strings.ToLower(strings.TrimSpace(subsystem)); subsystem == "" {
+			levels.Default = level
+		} else {
+			levels.Subsystems[subsystem] = level
+		}
+	}
+
+	return levels, nil
+}
+
+func parseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if strings.EqualFold(strings.TrimSpace(name), "warning") {
+		name = "warn"
+	}
+	err := level.UnmarshalText([]byte(strings.TrimSpace(name)))
+
+	return level, err
+}
+
+// ptermLevel converts a slog level to the matching pterm level
+func ptermLevel(level slog.Level) pterm.LogLevel {
+	switch {
+	case level <= slog.LevelDebug:
+		return pterm.LogLevelDebug
+	case level <= slog.LevelInfo:
+		return pterm.LogLevelInfo
+	case level <= slog.LevelWarn:
+		return pterm.LogLevelWarn
+	default:
+		return pterm.LogLevelError
+	}
+}
+
+// levelHandler drops records below its level before they reach the shared handler
+type levelHandler struct {
+	level slog.Level
+	next  slog.Handler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level && h.next.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.next.Handle(ctx, record)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{level: h.level, next: h.next.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{level: h.level, next: h.next.WithGroup(name)}
+}
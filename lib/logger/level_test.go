@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestParseLevels(t *testing.T) {
+	tests := []struct {
+		spec       string
+		def        slog.Level
+		want       slog.Level
+		subsystems map[string]slog.Level
+		err        bool
+	}{
+		{"", slog.LevelWarn, slog.LevelWarn, map[string]slog.Level{}, false},
+		{"debug", slog.LevelInfo, slog.LevelDebug, map[string]slog.Level{}, false},
+		{"firestore=debug", slog.LevelInfo, slog.LevelInfo, map[string]slog.Level{"firestore": slog.LevelDebug}, false},
+		{" Firestore = DEBUG , sync=warning,error ", slog.LevelInfo, slog.LevelError,
+			map[string]slog.Level{"firestore": slog.LevelDebug, "sync": slog.LevelWarn}, false},
+		{"=warn", slog.LevelInfo, slog.LevelWarn, map[string]slog.Level{}, false},
+		{"github=info,,", slog.LevelInfo, slog.LevelInfo, map[string]slog.Level{"github": slog.LevelInfo}, false},
+		{"sync=loud", slog.LevelInfo, 0, nil, true},
+		{"verbose", slog.LevelInfo, 0, nil, true},
+		{"sync=", slog.LevelInfo, 0, nil, true},
+		{"sync=debug=info", slog.LevelInfo, 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			levels, err := ParseLevels(tt.spec, tt.def)
+			if tt.err {
+				if err == nil {
+					t.Errorf("ParseLevels(%q) = %+v, want an error", tt.spec, levels)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLevels(%q) error = %v", tt.spec, err)
+			}
+			if levels.Default != tt.want || !maps.Equal(levels.Subsystems, tt.subsystems) {
+				t.Errorf("ParseLevels(%q) = %+v, want default %s and %v", tt.spec, levels, tt.want, tt.subsystems)
+			}
+		})
+	}
+}
+
+func TestLevelsLevelAndMin(t *testing.T) {
+	levels := Levels{Default: slog.LevelWarn, Subsystems: map[string]slog.Level{"firestore": slog.LevelDebug, "sync": slog.LevelError}}
+
+	if got := levels.Level("firestore"); got != slog.LevelDebug {
+		t.Errorf("Level(firestore) = %s, want DEBUG", got)
+	}
+	if got := levels.Level("github"); got != slog.LevelWarn {
+		t.Errorf("Level(github) = %s, want the default WARN", got)
+	}
+	if got := levels.Min(); got != slog.LevelDebug {
+		t.Errorf("Min() = %s, want DEBUG", got)
+	}
+	if got := (Levels{Default: slog.LevelInfo}).Min(); got != slog.LevelInfo {
+		t.Errorf("Min() without subsystems = %s, want INFO", got)
+	}
+}
+
+// withLevels routes For through a JSON handler at the given levels for the duration of the test
+func withLevels(t *testing.T, levels Levels) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	savedBase, savedCurrent := base, current
+	base = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: levels.Min()}))
+	current = levels
+	t.Cleanup(func() { base, current = savedBase, savedCurrent })
+
+	return &buf
+}
+
+func TestForRoutesBySubsystem(t *testing.T) {
+	buf := withLevels(t, Levels{Default: slog.LevelInfo, Subsystems: map[string]slog.Level{"firestore": slog.LevelDebug, "sync": slog.LevelWarn}})
+
+	For("firestore").Debug("firestore debug")
+	For("sync").Info("sync info")
+	For("sync").Warn("sync warn")
+	For("github").Debug("github debug")
+	For("github").Info("github info")
+	// Derived loggers keep the subsystem's level
+	For("sync").With("repo", "a/b").Info("sync info with attrs")
+	For("firestore").WithGroup("tx").Debug("firestore debug in group", "n", 1)
+
+	var got []string
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, record["subsystem"].(string)+": "+record["msg"].(string))
+	}
+
+	want := []string{"firestore: firestore debug", "sync: sync warn", "github: github info", "firestore: firestore debug in group"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("logged\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLevelHandlerEnabled(t *testing.T) {
+	h := &levelHandler{level: slog.LevelWarn, next: slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelDebug})}
+	ctx := context.Background()
+
+	if h.Enabled(ctx, slog.LevelInfo) {
+		t.Error("INFO enabled below the subsystem level")
+	}
+	if !h.Enabled(ctx, slog.LevelError) {
+		t.Error("ERROR disabled above the subsystem level")
+	}
+
+	// The shared handler still has the last word
+	strict := &levelHandler{level: slog.LevelDebug, next: slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelError})}
+	if strict.Enabled(ctx, slog.LevelInfo) {
+		t.Error("INFO enabled although the shared handler only allows ERROR")
+	}
+}
@@ -1,51 +1,166 @@
 package logger
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
 	"log/slog"
+	"os"
+	"slices"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/viper"
 )
 
+// Supported log formats
+const (
+	FormatPterm = "pterm"
+	FormatText  = "text"
+	FormatJSON  = "json"
+)
+
 // Global Log variable
 var Log *slog.Logger = slog.Default()
 
+// CommandSubsystem is the subsystem of records written through Log, usually by commands
+const CommandSubsystem = "cmd"
+
+var (
+	// base is the unfiltered logger that Log and the subsystem loggers share
+	base = slog.Default()
+	// current holds the levels set by SetLogger
+	current = Levels{Default: slog.LevelInfo}
+)
+
+// RunID identifies every log record written by this invocation of pdt
+var RunID = newRunID()
+
 // SetLogger sets the default logger verbosity level and returns a [log/slog.Logger](https://pkg.go.dev/log/slog#Logger)
-// verbosity is an integer from 0 to 3, where higher numbers are more verbose
-func SetLogger(verbosity int) *slog.Logger {
+// verbosity is an integer from 0 to 3, where higher numbers are more verbose.
+// The log.level config key may override the level, globally or per subsystem (see ParseLevels).
+// The output format and destination are taken from the log.format and log.file config keys,
+// and every record carries the run ID, the command and the subsystem that wrote it ("cmd" for Log).
+func SetLogger(verbosity int, command string) (*slog.Logger, error) {
 	if !viper.GetBool("color") {
 		pterm.DisableColor()
 	}
 
+	// Output that doesn't go through slog (pterm printers and the standard logger) is redacted too
+	pterm.SetDefaultOutput(NewRedactingWriter(os.Stdout))
+	log.SetOutput(NewRedactingWriter(os.Stderr))
+
 	// Limit verbosity to 3
 	if verbosity > 3 {
 		verbosity = 3
 	}
 
-	// Create a new slog handler with the default PTerm logger
-	handler := pterm.NewSlogHandler(&pterm.DefaultLogger)
-
-	// Create a new slog logger with the handler
-	logger := slog.New(handler)
-
-	var level pterm.LogLevel
+	var def slog.Level
 	switch verbosity {
 	case 3:
-		level = pterm.LogLevelDebug
+		def = slog.LevelDebug
 	case 2:
-		level = pterm.LogLevelInfo
+		def = slog.LevelInfo
 	case 1:
-		level = pterm.LogLevelWarn
+		def = slog.LevelWarn
 	default:
-		level = pterm.LogLevelError
+		def = slog.LevelError
 	}
 
-	// Change the log level to debug to enable debug messages
-	pterm.DefaultLogger.Level = level
+	// log.level may raise or lower the default and set levels per subsystem
+	levels, err := ParseLevels(viper.GetString("log.level"), def)
+	if err != nil {
+		return Log, err
+	}
+
+	format := viper.GetString("log.format")
+	if format == "" {
+		format = FormatPterm
+	}
+
+	loadSecrets()
+
+	var out io.Writer = os.Stderr
+	if file := viper.GetString("log.file"); file != "" {
+		rotating, err := OpenRotatingFile(file, viper.GetInt64("log.max_size"), viper.GetInt("log.max_backups"))
+		if err != nil {
+			return Log, err
+		}
+		out = rotating
+
+		// Colors and terminal formatting don't belong in a file
+		if format == FormatPterm {
+			format = FormatText
+		}
+	}
+
+	var handler slog.Handler
+	opts := &slog.HandlerOptions{Level: levels.Min()}
+	switch format {
+	case FormatPterm:
+		// Change the log level to enable the requested messages
+		pterm.DefaultLogger.Level = ptermLevel(levels.Min())
+		handler = pterm.NewSlogHandler(&pterm.DefaultLogger)
+	case FormatText:
+		handler = slog.NewTextHandler(out, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, opts)
+	default:
+		return Log, fmt.Errorf("unknown log format %q (expected %s, %s or %s)", format, FormatPterm, FormatText, FormatJSON)
+	}
 
-	Log = logger
+	// Create a new slog logger with the handler, redacting secrets on every output path
+	logger := slog.New(NewRedactingHandler(&attrHandler{next: handler})).With("run_id", RunID, "command", command)
+
+	base, current = logger, levels
+	Log = For(CommandSubsystem)
+
+	return Log, nil
+}
+
+// For returns a logger for a subsystem (usually a lib package), tagging each record with its name
+// and filtering it at the subsystem's own level
+func For(subsystem string) *slog.Logger {
+	handler := &levelHandler{level: current.Level(subsystem), next: base.Handler()}
+
+	return slog.New(handler).With("subsystem", subsystem)
+}
+
+// attrHandler adds the attributes given to With to each record itself, the same way for every output format.
+// pterm's handler replaces its attributes on each With call instead, which would drop the run ID once a subsystem is added.
+type attrHandler struct {
+	attrs []slog.Attr
+	next  slog.Handler
+}
+
+func (h *attrHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *attrHandler) Handle(ctx context.Context, record slog.Record) error {
+	if len(h.attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(h.attrs...)
+	}
+
+	return h.next.Handle(ctx, record)
+}
+
+func (h *attrHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &attrHandler{attrs: append(slices.Clone(h.attrs), attrs...), next: h.next}
+}
+
+func (h *attrHandler) WithGroup(name string) slog.Handler {
+	// Attributes added so far stay outside the group
+	return &attrHandler{next: h.next.WithAttrs(h.attrs).WithGroup(name)}
+}
 
-	return logger
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
 }
 
 func TestLogger() {
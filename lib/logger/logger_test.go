@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestAttrHandlerKeepsEveryAttribute(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(&attrHandler{next: slog.NewJSONHandler(&buf, nil)}).
+		With("run_id", "abc", "command", "sync").
+		With("subsystem", "firestore")
+
+	logger.Info("hello", "count", 2)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{"run_id": "abc", "command": "sync", "subsystem": "firestore", "count": 2.0} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %v", key, record[key], want)
+		}
+	}
+}
+
+// replacingHandler mimics pterm's handler, whose WithAttrs replaces earlier attributes
+type replacingHandler struct {
+	attrs   []slog.Attr
+	records *[]slog.Record
+}
+
+func (h *replacingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *replacingHandler) Handle(_ context.Context, record slog.Record) error {
+	record.AddAttrs(h.attrs...)
+	*h.records = append(*h.records, record)
+	return nil
+}
+
+func (h *replacingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &replacingHandler{attrs: attrs, records: h.records}
+}
+
+func (h *replacingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestAttrHandlerWithReplacingHandler(t *testing.T) {
+	var records []slog.Record
+	logger := slog.New(&attrHandler{next: &replacingHandler{records: &records}}).
+		With("run_id", "abc").
+		With("subsystem", "sync")
+
+	logger.Info("hello")
+
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	got := map[string]string{}
+	records[0].Attrs(func(a slog.Attr) bool {
+		got[a.Key] = a.Value.String()
+		return true
+	})
+	if got["run_id"] != "abc" || got["subsystem"] != "sync" {
+		t.Errorf("attributes = %v, want run_id and subsystem", got)
+	}
+}
@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/config"
+	"github.com/spf13/viper"
+)
+
+// secretPatterns match secrets wherever they appear, even when they are not configured
+var secretPatterns = []*regexp.Regexp{
+	// PEM private key blocks, including ones embedded in JSON with escaped newlines
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	// GitHub personal access, OAuth, app and refresh tokens
+	regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})\b`),
+}
+
+// authorizationPattern matches the credentials of an Authorization header, keeping the header name and scheme.
+// It covers headers, JSON and the %v form of http.Header (map[Authorization:[Bearer ...]]), and matches
+// credentials that were already redacted so redacting twice gives the same result.
+var authorizationPattern = regexp.MustCompile(`(?i)(authorization"?\s*[:=]\s*"?\[??(?:bearer |token |basic )?)(?:` +
+	regexp.QuoteMeta(config.Redacted) + `|[^\s",}\[\]]+)`)
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// loadSecrets collects the values of every configured secret key (private keys, tokens, ...) so they can be redacted verbatim
+func loadSecrets() {
+	keys := append(viper.AllKeys(), "github.token")
+
+	var values []string
+	for _, key := range keys {
+		if !config.IsSecret(key) {
+			continue
+		}
+		// Very short values would redact unrelated text
+		if v := viper.GetString(key); len(v) >= 8 {
+			values = append(values, v)
+		}
+	}
+
+	secretsMu.Lock()
+	secrets = values
+	secretsMu.Unlock()
+}
+
+// Redact removes configured secret values, private key blocks, GitHub tokens and Authorization header credentials from s
+func Redact(s string) string {
+	secretsMu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, config.Redacted)
+	}
+	secretsMu.RUnlock()
+
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, config.Redacted)
+	}
+
+	return authorizationPattern.ReplaceAllString(s, "${1}"+config.Redacted)
+}
+
+// RedactingHandler is a slog.Handler that redacts secrets from the message and attributes of every record
+type RedactingHandler struct {
+	next slog.Handler
+}
+
+// NewRedactingHandler wraps next so that secrets never reach it
+func NewRedactingHandler(next slog.Handler) *RedactingHandler {
+	return &RedactingHandler{next: next}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+
+	return &RedactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	if config.IsSecret(attr.Key) {
+		return slog.String(attr.Key, config.Redacted)
+	}
+
+	v := attr.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, a := range group {
+			redacted[i] = redactAttr(a)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(v.String()))
+	case slog.KindAny:
+		s := fmt.Sprint(v.Any())
+		if r := Redact(s); r != s {
+			return slog.String(attr.Key, r)
+		}
+	}
+
+	return slog.Attr{Key: attr.Key, Value: v}
+}
+
+// redactingWriter redacts secrets from everything written through it
+type redactingWriter struct {
+	w io.Writer
+}
+
+// NewRedactingWriter wraps w so that secrets are redacted from everything written to it
+func NewRedactingWriter(w io.Writer) io.Writer {
+	return redactingWriter{w: w}
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+
+	// Report the original length so callers don't treat redaction as a short write
+	return len(p), nil
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Defaults used when log.max_size or log.max_backups are not set
+const (
+	DefaultMaxSize    = 10 << 20
+	DefaultMaxBackups = 3
+)
+
+// RotatingFile is an append-only log file that is rotated once it grows past a size limit.
+// Rotated files are renamed to <path>.1, <path>.2, ... with the oldest beyond maxBackups removed.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// OpenRotatingFile opens (or creates) path for appending
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxBackups <= 0 {
+		maxBackups = DefaultMaxBackups
+	}
+
+	r := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Write appends p to the file, rotating it first if p would take it past the size limit
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	_ = os.Remove(backupName(r.path, r.maxBackups))
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(backupName(r.path, i), backupName(r.path, i+1))
+	}
+	if err := os.Rename(r.path, backupName(r.path, 1)); err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
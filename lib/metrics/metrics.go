@@ -0,0 +1,290 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Metric types, as named in the Prometheus text format
+const (
+	counter   = "counter"
+	gauge     = "gauge"
+	histogram = "histogram"
+)
+
+// Metric names
+const (
+	RunDuration       = "pdt_run_duration_seconds"
+	LastRun           = "pdt_last_run_timestamp_seconds"
+	LastRunSuccess    = "pdt_last_run_success"
+	Errors            = "pdt_errors_total"
+	FirestoreReads    = "pdt_firestore_reads_total"
+	FirestoreWrites   = "pdt_firestore_writes_total"
+	FetchDuration     = "pdt_fetch_duration_seconds"
+	ReposProcessed    = "pdt_repos_processed_total"
+	RepoProblems      = "pdt_repo_problems_total"
+	SyncDocuments     = "pdt_sync_documents_total"
+	LinkChecks        = "pdt_link_checks_total"
+	LinkCheckDuration = "pdt_link_check_duration_seconds"
+	FileVerifications = "pdt_file_verifications_total"
+	DownloadedBytes   = "pdt_downloaded_bytes_total"
+	BackupDocuments   = "pdt_backup_documents"
+	BackupSize        = "pdt_backup_size_bytes"
+)
+
+// fetchBuckets are the histogram buckets for HTTP fetch latency, in seconds
+var fetchBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type family struct {
+	name    string
+	typ     string
+	help    string
+	buckets []float64
+	series  map[string]*series
+}
+
+type series struct {
+	labels []string
+	value  float64
+	counts []uint64
+	count  uint64
+}
+
+var (
+	mu       sync.Mutex
+	families = map[string]*family{}
+)
+
+func init() {
+	register(RunDuration, gauge, "Duration of the last run of a command in seconds.", nil)
+	register(LastRun, gauge, "Unix time at which the last run of a command finished.", nil)
+	register(LastRunSuccess, gauge, "Whether the last run of a command succeeded (1) or failed (0).", nil)
+	register(Errors, counter, "Errors by subsystem and class.", nil)
+	register(FirestoreReads, counter, "Firestore documents read.", nil)
+	register(FirestoreWrites, counter, "Firestore documents written, including audit entries.", nil)
+	register(FetchDuration, histogram, "Latency of HTTP fetches from GitHub in seconds.", fetchBuckets)
+	register(ReposProcessed, counter, "Repositories and info files processed by sync.", nil)
+	register(RepoProblems, counter, "Repositories found renamed, transferred, archived, disabled or deleted, by status.", nil)
+	register(SyncDocuments, counter, "Documents processed by sync, by kind and result.", nil)
+	register(LinkChecks, counter, "Links checked, by result.", nil)
+	register(LinkCheckDuration, histogram, "Latency of link checks in seconds.", fetchBuckets)
+	register(FileVerifications, counter, "Files downloaded and hashed by verify, by result.", nil)
+	register(DownloadedBytes, counter, "Bytes downloaded by verify.", nil)
+	register(BackupDocuments, gauge, "Documents in the last backup archive.", nil)
+	register(BackupSize, gauge, "Size of the last backup archive in bytes.", nil)
+}
+
+func register(name, typ, help string, buckets []float64) {
+	families[name] = &family{name: name, typ: typ, help: help, buckets: buckets, series: map[string]*series{}}
+}
+
+// get returns the series of a metric for the given label pairs (key, value, key, value, ...), creating it if needed.
+// The caller must hold mu.
+func get(name string, labels []string) *series {
+	f, ok := families[name]
+	if !ok {
+		panic(fmt.Sprintf("metrics: unknown metric %q", name))
+	}
+	if len(labels)%2 != 0 {
+		panic(fmt.Sprintf("metrics: odd number of label values for %q", name))
+	}
+
+	key := strings.Join(labels, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: slices.Clone(labels), counts: make([]uint64, len(f.buckets))}
+		f.series[key] = s
+	}
+
+	return s
+}
+
+// Add adds v to a counter
+func Add(name string, v float64, labels ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	get(name, labels).value += v
+}
+
+// Inc adds one to a counter
+func Inc(name string, labels ...string) {
+	Add(name, 1, labels...)
+}
+
+// Set sets a gauge to v
+func Set(name string, v float64, labels ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	get(name, labels).value = v
+}
+
+// Observe records a value in a histogram
+func Observe(name string, v float64, labels ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s := get(name, labels)
+	for i, bound := range families[name].buckets {
+		if v <= bound {
+			s.counts[i]++
+		}
+	}
+	s.value += v
+	s.count++
+}
+
+// Since records the time elapsed since start in a histogram
+func Since(name string, start time.Time, labels ...string) {
+	Observe(name, time.Since(start).Seconds(), labels...)
+}
+
+// Error counts err against a subsystem, classified by ErrorClass
+func Error(subsystem string, err error) {
+	if err != nil {
+		Inc(Errors, "subsystem", subsystem, "class", ErrorClass(err))
+	}
+}
+
+// ErrorClass returns a short, low-cardinality class for an error (e.g. "timeout", "network", "not_found")
+func ErrorClass(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+
+	if code := status.Code(err); code != codes.Unknown {
+		return toSnake(code.String())
+	}
+
+	return "other"
+}
+
+func toSnake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Write writes every metric in the Prometheus text exposition format
+func Write(w io.Writer) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(families)) {
+		f := families[name]
+		if len(f.series) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.typ)
+
+		keys := slices.Collect(maps.Keys(f.series))
+		sort.Strings(keys)
+		for _, key := range keys {
+			s := f.series[key]
+			if f.typ != histogram {
+				fmt.Fprintf(&b, "%s%s %s\n", f.name, labelString(s.labels), formatValue(s.value))
+				continue
+			}
+
+			for i, bound := range f.buckets {
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", f.name, labelString(append(slices.Clone(s.labels), "le", formatValue(bound))), s.counts[i])
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", f.name, labelString(append(slices.Clone(s.labels), "le", "+Inf")), s.count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", f.name, labelString(s.labels), formatValue(s.value))
+			fmt.Fprintf(&b, "%s_count%s %d\n", f.name, labelString(s.labels), s.count)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteFile atomically writes every metric to file, for the node_exporter textfile collector
+func WriteFile(file string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// The collector must never see a partially written file
+	return os.Rename(tmp.Name(), file)
+}
+
+func labelString(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+escapeLabel(labels[i+1])+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// labelEscaper escapes the only characters the Prometheus text format escapes in label values
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel formats a label value for the text format, which must be valid UTF-8 and is otherwise written as is
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(strings.ToValidUTF8(v, "\uFFFD"))
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLabelString(t *testing.T) {
+	tests := []struct {
+		labels []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"kind", "mod"}, `{kind="mod"}`},
+		{[]string{"path", `C:\mods`}, `{path="C:\\mods"}`},
+		{[]string{"msg", `say "hi"`}, `{msg="say \"hi\""}`},
+		{[]string{"msg", "two\nlines"}, `{msg="two\nlines"}`},
+		// Unlike Go string escaping, tabs and non-ASCII characters are written as is
+		{[]string{"name", "café\tbar"}, "{name=\"café\tbar\"}"},
+		{[]string{"name", "bad\xffbyte"}, "{name=\"bad\uFFFDbyte\"}"},
+		{[]string{"a", "1", "b", "2"}, `{a="1",b="2"}`},
+	}
+
+	for _, tt := range tests {
+		if got := labelString(tt.labels); got != tt.want {
+			t.Errorf("labelString(%q) = %s, want %s", tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestFinishRecordsARunOnce(t *testing.T) {
+	const cmd = "pdt test"
+	success := func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return get(LastRunSuccess, []string{"command", cmd}).value
+	}
+
+	Start(cmd)
+	Finish(errors.New("sync failed"))
+	if got := success(); got != 0 {
+		t.Fatalf("%s = %v after a failed run, want 0", LastRunSuccess, got)
+	}
+
+	// A second Finish without a new Start, as from the root command after a loop, must not overwrite the outcome
+	Finish(nil)
+	if got := success(); got != 0 {
+		t.Errorf("%s = %v after finishing twice, want the failed run's 0", LastRunSuccess, got)
+	}
+
+	Start(cmd)
+	Finish(nil)
+	if got := success(); got != 1 {
+		t.Errorf("%s = %v after a successful run, want 1", LastRunSuccess, got)
+	}
+}
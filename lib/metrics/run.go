@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/spf13/viper"
+)
+
+// subsystem names this package in log records
+const subsystem = "metrics"
+
+var (
+	command string
+	started time.Time
+)
+
+// Start marks the beginning of a run of command
+func Start(cmd string) {
+	command, started = cmd, time.Now()
+}
+
+// Finish records the duration and outcome of the run started by Start and writes
+// the metrics file, if one is configured. Long-lived commands may call Start and Finish around every iteration.
+// Finish does nothing when no run is active, so a run is only ever recorded once.
+func Finish(err error) {
+	if started.IsZero() {
+		return
+	}
+	defer func() { started = time.Time{} }()
+
+	success := 1.0
+	if err != nil {
+		success = 0
+	}
+
+	Set(RunDuration, time.Since(started).Seconds(), "command", command)
+	Set(LastRun, float64(time.Now().Unix()), "command", command)
+	Set(LastRunSuccess, success, "command", command)
+
+	if file := viper.GetString("metrics.file"); file != "" {
+		if err := WriteFile(file); err != nil {
+			logger.For(subsystem).Error(fmt.Sprintf("Unable to write metrics to %s: %v", file, err))
+		}
+	}
+}
+
+// Handler serves every metric in the Prometheus text exposition format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := Write(w); err != nil {
+			logger.For(subsystem).Error(fmt.Sprintf("Unable to write metrics: %v", err))
+		}
+	})
+}
+
+// Serve exposes the metrics on /metrics at addr (e.g. ":9090") until the process exits
+func Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	logger.For(subsystem).Info(fmt.Sprintf("Serving metrics on %s/metrics", addr))
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.For(subsystem).Error(fmt.Sprintf("Unable to serve metrics: %v", err))
+		}
+	}()
+}
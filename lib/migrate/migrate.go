@@ -0,0 +1,186 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+)
+
+// subsystem names this package in log records
+const subsystem = "migrate"
+
+// VersionField is the meta/status field holding the current schema version
+const VersionField = "schemaVersion"
+
+// Migration transforms the database from Version-1 to Version (Up) and back again (Down)
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(*firestore.Tx) error
+	Down    func(*firestore.Tx) error
+}
+
+var registry []Migration
+
+// Register adds a migration to the registry.
+// It panics if the version is already registered, as that is a programming error.
+func Register(m Migration) {
+	for _, r := range registry {
+		if r.Version == m.Version {
+			panic(fmt.Sprintf("migration %d registered twice (%q and %q)", m.Version, r.Name, m.Name))
+		}
+	}
+
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Version < registry[j].Version })
+}
+
+// Migrations returns every registered migration in version order
+func Migrations() []Migration {
+	return registry
+}
+
+// Latest returns the highest registered schema version
+func Latest() int {
+	if len(registry) == 0 {
+		return 0
+	}
+
+	return registry[len(registry)-1].Version
+}
+
+// Current reads the schema version stored in meta/status
+func Current(ctx context.Context) (int, error) {
+	var version int
+	_, err := firestore.RunTransaction(ctx, true, func(tx *firestore.Tx) error {
+		var err error
+		version, err = readVersion(tx)
+		return err
+	})
+
+	return version, err
+}
+
+// Step is a migration applied (or, in a dry run, planned) by Up or Down along with the writes it made
+type Step struct {
+	Migration Migration
+	Direction string
+	Writes    []firestore.Write
+}
+
+// Up applies every pending migration up to and including target (0 for the latest), each in its own transaction
+func Up(ctx context.Context, target int, dryrun bool) ([]Step, error) {
+	if target == 0 {
+		target = Latest()
+	}
+
+	current, err := Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []Step
+	for _, m := range registry {
+		if m.Version <= current || m.Version > target {
+			continue
+		}
+
+		writes, err := apply(ctx, m.Version-1, m.Version, m.Up, dryrun)
+		if err != nil {
+			return steps, fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		steps = append(steps, Step{Migration: m, Direction: "up", Writes: writes})
+	}
+
+	return steps, nil
+}
+
+// Down reverts applied migrations, newest first, until the schema is at target.
+// A negative target reverts only the most recently applied migration.
+func Down(ctx context.Context, target int, dryrun bool) ([]Step, error) {
+	current, err := Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if target < 0 {
+		target = 0
+		for _, m := range registry {
+			if m.Version < current {
+				target = m.Version
+			}
+		}
+	}
+
+	var steps []Step
+	for i := len(registry) - 1; i >= 0; i-- {
+		m := registry[i]
+		if m.Version > current || m.Version <= target {
+			continue
+		}
+
+		previous := 0
+		if i > 0 {
+			previous = registry[i-1].Version
+		}
+
+		writes, err := apply(ctx, m.Version, previous, m.Down, dryrun)
+		if err != nil {
+			return steps, fmt.Errorf("migration %d (%s) down: %w", m.Version, m.Name, err)
+		}
+		steps = append(steps, Step{Migration: m, Direction: "down", Writes: writes})
+	}
+
+	return steps, nil
+}
+
+// apply runs fn in a transaction and records the new schema version in the same transaction.
+// The stored version must still be from, so two concurrent runs cannot apply the same migration.
+func apply(ctx context.Context, from, to int, fn func(*firestore.Tx) error, dryrun bool) ([]firestore.Write, error) {
+	statusPath, err := firestore.MetaPath(firestore.MetaStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(subsystem).Info(fmt.Sprintf("Migrating schema from version %d to %d", from, to))
+	return firestore.RunTransaction(ctx, dryrun, func(tx *firestore.Tx) error {
+		version, err := readVersion(tx)
+		if err != nil {
+			return err
+		}
+		// In a dry run earlier steps were not applied, so the stored version lags behind the plan
+		if version != from && !dryrun {
+			return fmt.Errorf("schema is at version %d, expected %d", version, from)
+		}
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		return tx.Merge(statusPath, map[string]any{VersionField: int64(to)})
+	})
+}
+
+func readVersion(tx *firestore.Tx) (int, error) {
+	statusPath, err := firestore.MetaPath(firestore.MetaStatus)
+	if err != nil {
+		return 0, err
+	}
+
+	status, err := tx.Get(statusPath)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := status[VersionField].(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("%s.%s has unexpected type %T", statusPath, VersionField, v)
+	}
+}
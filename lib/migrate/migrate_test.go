@@ -0,0 +1,62 @@
+package migrate
+
+import (
+	"slices"
+	"testing"
+)
+
+// withRegistry runs the test against an empty registry and restores the real one afterwards
+func withRegistry(t *testing.T) {
+	t.Helper()
+
+	saved := registry
+	registry = nil
+	t.Cleanup(func() { registry = saved })
+}
+
+func TestRegisterKeepsVersionOrder(t *testing.T) {
+	withRegistry(t)
+
+	if Latest() != 0 {
+		t.Errorf("Latest() = %d on an empty registry, want 0", Latest())
+	}
+
+	for _, v := range []int{3, 1, 2} {
+		Register(Migration{Version: v, Name: "test"})
+	}
+
+	var versions []int
+	for _, m := range Migrations() {
+		versions = append(versions, m.Version)
+	}
+	if !slices.Equal(versions, []int{1, 2, 3}) {
+		t.Errorf("versions = %v, want [1 2 3]", versions)
+	}
+	if Latest() != 3 {
+		t.Errorf("Latest() = %d, want 3", Latest())
+	}
+}
+
+func TestRegisterPanicsOnDuplicateVersion(t *testing.T) {
+	withRegistry(t)
+
+	Register(Migration{Version: 1, Name: "first"})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering version 1 twice did not panic")
+		}
+	}()
+	Register(Migration{Version: 1, Name: "second"})
+}
+
+func TestBuiltinMigrationsAreComplete(t *testing.T) {
+	for i, m := range Migrations() {
+		if m.Version != i+1 {
+			t.Errorf("migration %q has version %d, want %d (versions must be consecutive)", m.Name, m.Version, i+1)
+		}
+		if m.Name == "" || m.Up == nil || m.Down == nil {
+			t.Errorf("migration %d must have a name, Up and Down", m.Version)
+		}
+	}
+}
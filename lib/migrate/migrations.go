@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+)
+
+// Migrations are registered here in version order. Never change or renumber a migration
+// once it has been applied to a database; add a new one instead.
+func init() {
+	Register(Migration{
+		Version: 1,
+		Name:    "add moderation field to every mod and tool",
+		Up:      addModeration,
+		Down:    removeModeration,
+	})
+}
+
+// addModeration writes an explicit, empty moderation field on documents that predate moderation,
+// so queries such as moderation.hidden == false match every document
+func addModeration(tx *firestore.Tx) error {
+	return eachEntry(tx, func(path string, data map[string]any) error {
+		if _, ok := data["moderation"]; ok {
+			return nil
+		}
+
+		return tx.Merge(path, map[string]any{"moderation": map[string]any{
+			"hidden":   false,
+			"featured": false,
+			"blocked":  false,
+		}})
+	})
+}
+
+// removeModeration removes moderation fields that were never used
+func removeModeration(tx *firestore.Tx) error {
+	return eachEntry(tx, func(path string, data map[string]any) error {
+		m, ok := data["moderation"].(map[string]any)
+		if !ok || m["hidden"] == true || m["featured"] == true || m["blocked"] == true || m["reason"] != nil {
+			return nil
+		}
+
+		after := make(map[string]any, len(data))
+		for k, v := range data {
+			if k != "moderation" {
+				after[k] = v
+			}
+		}
+		return tx.Set(path, after)
+	})
+}
+
+// eachEntry calls fn for every mod and tool document
+func eachEntry(tx *firestore.Tx, fn func(path string, data map[string]any) error) error {
+	for _, kind := range models.Kinds {
+		collection, err := firestore.Collection(kind)
+		if err != nil {
+			return err
+		}
+
+		docs, err := tx.Documents(collection)
+		if err != nil {
+			return err
+		}
+		for path, data := range docs {
+			if err := fn(path, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Fields returns the top-level Firestore fields of a document keyed by their firestore tag.
+// Fields tagged omitempty are left out when they hold their zero value.
+func Fields(doc any) map[string]any {
+	v := reflect.Indirect(reflect.ValueOf(doc))
+	t := v.Type()
+	fields := make(map[string]any, t.NumField())
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, omitEmpty := firestoreTag(sf)
+		if name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		fields[name] = fv.Interface()
+	}
+
+	return fields
+}
+
+// firestoreTag returns the field name and omitempty option of a struct field
+func firestoreTag(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("firestore")
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = sf.Name
+	}
+
+	return name, strings.Contains(opts, "omitempty")
+}
+
+// Equal reports whether two documents have the same Firestore fields
+func Equal(a, b Document) bool {
+	return reflect.DeepEqual(Fields(a), Fields(b))
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FileInfo records what the server reported for a file URL at the last sync
+type FileInfo struct {
+	// Size is the length of the file in bytes, or nil if the server did not report one
+	Size        *int64 `firestore:"size,omitempty" json:"size,omitempty" yaml:"size,omitempty"`
+	ContentType string `firestore:"content_type,omitempty" json:"content_type,omitempty" yaml:"content_type,omitempty"`
+	// Problem describes why the file looks wrong, e.g. an HTML page instead of an archive, or an empty file
+	Problem string `firestore:"problem,omitempty" json:"problem,omitempty" yaml:"problem,omitempty"`
+
+	// SHA256 is the hex digest of the file downloaded by pdt verify files at VerifiedAt
+	SHA256     string    `firestore:"sha256,omitempty" json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	VerifiedAt time.Time `firestore:"verified_at,omitempty" json:"verified_at,omitzero" yaml:"verified_at,omitempty"`
+	// VerifiedVersion is the version of the mod when the file was verified
+	VerifiedVersion string `firestore:"verified_version,omitempty" json:"verified_version,omitempty" yaml:"verified_version,omitempty"`
+	// ChangedWithoutBump is set when the content changed since an earlier verification but the version did not
+	ChangedWithoutBump bool `firestore:"changed_without_bump,omitempty" json:"changed_without_bump,omitempty" yaml:"changed_without_bump,omitempty"`
+}
+
+// RecordHash stores the digest of a verified download of the file, flagging content that changed
+// while the mod kept the version it had at the previous verification
+func (fi *FileInfo) RecordHash(sha256, version string, at time.Time) {
+	if fi.VerifiedVersion != version {
+		fi.ChangedWithoutBump = false
+	} else if fi.SHA256 != "" && fi.SHA256 != sha256 {
+		fi.ChangedWithoutBump = true
+	}
+
+	fi.SHA256, fi.VerifiedVersion, fi.VerifiedAt = sha256, version, at
+}
+
+// FileProblem describes what is wrong with a file of the given type, size and content type, or returns "".
+// Every file type (exmodz, zip, pak, ...) is a binary download, so an HTML page is an error page or a
+// landing page rather than the file itself.
+func FileProblem(fileType string, size *int64, contentType string) string {
+	switch {
+	case size != nil && *size == 0:
+		return "empty file"
+	case contentType == "text/html" || contentType == "application/xhtml+xml":
+		return fmt.Sprintf("served %s instead of the %s file", contentType, strings.ToLower(fileType))
+	}
+
+	return ""
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordHash(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		before  FileInfo
+		sha256  string
+		version string
+		changed bool
+	}{
+		{"first verification", FileInfo{}, "aaa", "1.0", false},
+		{"same content and version", FileInfo{SHA256: "aaa", VerifiedVersion: "1.0"}, "aaa", "1.0", false},
+		{"content changed without a version bump", FileInfo{SHA256: "aaa", VerifiedVersion: "1.0"}, "bbb", "1.0", true},
+		{"content changed with a version bump", FileInfo{SHA256: "aaa", VerifiedVersion: "1.0"}, "bbb", "1.1", false},
+		{"bump clears an earlier flag", FileInfo{SHA256: "bbb", VerifiedVersion: "1.0", ChangedWithoutBump: true}, "bbb", "1.1", false},
+		{"flag stays until the version is bumped", FileInfo{SHA256: "bbb", VerifiedVersion: "1.0", ChangedWithoutBump: true}, "bbb", "1.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fi := tt.before
+			fi.RecordHash(tt.sha256, tt.version, at)
+
+			if fi.ChangedWithoutBump != tt.changed {
+				t.Errorf("ChangedWithoutBump = %t, want %t", fi.ChangedWithoutBump, tt.changed)
+			}
+			if fi.SHA256 != tt.sha256 || fi.VerifiedVersion != tt.version || !fi.VerifiedAt.Equal(at) {
+				t.Errorf("recorded %q, %q, %s; want %q, %q, %s", fi.SHA256, fi.VerifiedVersion, fi.VerifiedAt, tt.sha256, tt.version, at)
+			}
+		})
+	}
+}
+
+func TestFileProblem(t *testing.T) {
+	size := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		fileType    string
+		size        *int64
+		contentType string
+		want        string
+	}{
+		{"zip", size(1024), "application/zip", ""},
+		{"pak", nil, "application/octet-stream", ""},
+		{"pak", nil, "", ""},
+		{"zip", size(0), "application/zip", "empty file"},
+		{"EXMODZ", size(512), "text/html", "served text/html instead of the exmodz file"},
+		{"zip", nil, "application/xhtml+xml", "served application/xhtml+xml instead of the zip file"},
+	}
+
+	for _, tt := range tests {
+		if got := FileProblem(tt.fileType, tt.size, tt.contentType); got != tt.want {
+			t.Errorf("FileProblem(%q, %v, %q) = %q, want %q", tt.fileType, tt.size, tt.contentType, got, tt.want)
+		}
+	}
+}
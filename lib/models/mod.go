@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Mod represents a document in the mods collection
+type Mod struct {
+	Name            string              `firestore:"name" json:"name" yaml:"name"`
+	Author          string              `firestore:"author" json:"author" yaml:"author"`
+	Version         string              `firestore:"version" json:"version" yaml:"version"`
+	Compatibility   string              `firestore:"compatibility,omitempty" json:"compatibility,omitempty" yaml:"compatibility,omitempty"`
+	Description     string              `firestore:"description,omitempty" json:"description,omitempty" yaml:"description,omitempty"`
+	LongDescription string              `firestore:"long_description,omitempty" json:"long_description,omitempty" yaml:"long_description,omitempty"`
+	Files           map[string]string   `firestore:"files" json:"files" yaml:"files"`
+	ImageURL        string              `firestore:"imageURL,omitempty" json:"imageURL,omitempty" yaml:"imageURL,omitempty"`
+	ReadmeURL       string              `firestore:"readmeURL,omitempty" json:"readmeURL,omitempty" yaml:"readmeURL,omitempty"`
+	Release         *Release            `firestore:"release,omitempty" json:"release,omitempty" yaml:"release,omitempty"`
+	ResolvedRelease *ResolvedRelease    `firestore:"resolved_release,omitempty" json:"resolved_release,omitempty" yaml:"resolved_release,omitempty"`
+	FileInfo        map[string]FileInfo `firestore:"file_info,omitempty" json:"file_info,omitempty" yaml:"file_info,omitempty"`
+	CreatedAt       time.Time           `firestore:"created_at,omitempty" json:"created_at,omitzero" yaml:"created_at,omitempty"`
+	UpdatedAt       time.Time           `firestore:"updated_at,omitempty" json:"updated_at,omitzero" yaml:"updated_at,omitempty"`
+	Moderation      Moderation          `firestore:"moderation" json:"moderation,omitzero" yaml:"moderation,omitempty"`
+}
+
+// Validate reports every problem with the mod as a single joined error
+func (m *Mod) Validate() error {
+	err := validateEntry(m.Name, m.Author, m.Version, m.Files, m.ImageURL, m.ReadmeURL)
+	if m.Release != nil {
+		err = errors.Join(err, m.Release.Validate())
+	}
+
+	return err
+}
+
+// Key identifies the mod across syncs by its author and name
+func (m *Mod) Key() string {
+	return strings.ToLower(m.Author + "/" + m.Name)
+}
+
+// Moderated returns the moderation state of the mod
+func (m *Mod) Moderated() *Moderation {
+	return &m.Moderation
+}
+
+// Timestamps returns pointers to the created and updated times of the mod
+func (m *Mod) Timestamps() (createdAt, updatedAt *time.Time) {
+	return &m.CreatedAt, &m.UpdatedAt
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Supported document kinds
+const (
+	KindMod  = "mod"
+	KindTool = "tool"
+)
+
+// Kinds lists every supported document kind
+var Kinds = []string{KindMod, KindTool}
+
+// Document is implemented by every model stored in the mods and tools collections
+type Document interface {
+	Validate() error
+	Key() string
+	Moderated() *Moderation
+	Timestamps() (createdAt, updatedAt *time.Time)
+}
+
+// New returns an empty document for the given kind
+func New(kind string) (Document, error) {
+	switch kind {
+	case KindMod:
+		return &Mod{}, nil
+	case KindTool:
+		return &Tool{}, nil
+	default:
+		return nil, fmt.Errorf("unknown document kind %q (expected one of: %s)", kind, strings.Join(Kinds, ", "))
+	}
+}
+
+// validateEntry performs the validations shared by mods and tools
+func validateEntry(name, author, version string, files map[string]string, imageURL, readmeURL string) error {
+	var errs []error
+
+	if strings.TrimSpace(name) == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if strings.TrimSpace(author) == "" {
+		errs = append(errs, errors.New("author is required"))
+	}
+	if strings.TrimSpace(version) == "" {
+		errs = append(errs, errors.New("version is required"))
+	}
+
+	if len(files) == 0 {
+		errs = append(errs, errors.New("at least one file is required"))
+	}
+	for fileType, fileURL := range files {
+		if err := validateURL(fileURL); err != nil {
+			errs = append(errs, fmt.Errorf("files.%s: %w", fileType, err))
+		}
+	}
+
+	if imageURL != "" {
+		if err := validateURL(imageURL); err != nil {
+			errs = append(errs, fmt.Errorf("imageURL: %w", err))
+		}
+	}
+	if readmeURL != "" {
+		if err := validateURL(readmeURL); err != nil {
+			errs = append(errs, fmt.Errorf("readmeURL: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q is not an http(s) URL", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", raw)
+	}
+
+	return nil
+}
+
+// Clone returns a deep copy of doc
+func Clone(doc Document) (Document, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := reflect.New(reflect.TypeOf(doc).Elem()).Interface().(Document)
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Moderation holds the moderation state of a mod or tool.
+// It is owned by moderators and is never overwritten from a modinfo or toolinfo source.
+type Moderation struct {
+	Hidden      bool      `firestore:"hidden" json:"hidden" yaml:"hidden"`
+	Featured    bool      `firestore:"featured" json:"featured" yaml:"featured"`
+	Blocked     bool      `firestore:"blocked" json:"blocked" yaml:"blocked"`
+	Reason      string    `firestore:"reason,omitempty" json:"reason,omitempty" yaml:"reason,omitempty"`
+	Moderator   string    `firestore:"moderator,omitempty" json:"moderator,omitempty" yaml:"moderator,omitempty"`
+	ModeratedAt time.Time `firestore:"moderated_at,omitempty" json:"moderated_at,omitzero" yaml:"moderated_at,omitempty"`
+}
+
+// Moderation actions
+const (
+	ActionHide      = "hide"
+	ActionUnhide    = "unhide"
+	ActionFeature   = "feature"
+	ActionUnfeature = "unfeature"
+	ActionBlock     = "block"
+)
+
+// ModerationActions lists every supported moderation action
+var ModerationActions = []string{ActionHide, ActionUnhide, ActionFeature, ActionUnfeature, ActionBlock}
+
+// Apply performs a moderation action, recording who performed it, why and when
+func (m *Moderation) Apply(action, reason, moderator string, at time.Time) bool {
+	switch action {
+	case ActionHide:
+		m.Hidden = true
+	case ActionUnhide:
+		m.Hidden = false
+	case ActionFeature:
+		m.Featured = true
+	case ActionUnfeature:
+		m.Featured = false
+	case ActionBlock:
+		m.Blocked = true
+		m.Hidden = true
+		m.Featured = false
+	default:
+		return false
+	}
+
+	m.Reason = reason
+	m.Moderator = moderator
+	m.ModeratedAt = at
+
+	return true
+}
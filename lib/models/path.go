@@ -0,0 +1,191 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SetField sets the field addressed by a dotted path (e.g. "files.pak") to value,
+// coercing the string to the field's type
+func SetField(doc Document, path, value string) error {
+	return walk(doc, path, true, func(target reflect.Value, key string) error {
+		if target.Kind() == reflect.Map {
+			v, err := coerce(target.Type().Elem(), value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if target.IsNil() {
+				target.Set(reflect.MakeMap(target.Type()))
+			}
+			target.SetMapIndex(reflect.ValueOf(key), v)
+			return nil
+		}
+
+		if target.Kind() == reflect.Struct && target.Type() != timeType {
+			return fmt.Errorf("%s is a group of fields, set one of its members instead", path)
+		}
+
+		v, err := coerce(target.Type(), value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		target.Set(v)
+		return nil
+	})
+}
+
+// UnsetField resets the field addressed by a dotted path to its zero value, or removes the key from a map
+func UnsetField(doc Document, path string) error {
+	return walk(doc, path, false, func(target reflect.Value, key string) error {
+		if target.Kind() == reflect.Map {
+			if !target.IsNil() {
+				target.SetMapIndex(reflect.ValueOf(key), reflect.Value{})
+			}
+			return nil
+		}
+
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	})
+}
+
+// walk resolves path against doc and calls fn with the addressed field.
+// When the last path segment is a map key, fn receives the map and the key.
+// Nil groups on the way are only allocated when create is set. Otherwise the rest of the path is resolved against
+// a detached zero value, so the path is still checked but the document is left untouched.
+func walk(doc Document, path string, create bool, fn func(target reflect.Value, key string) error) error {
+	segments := strings.Split(path, ".")
+	v := reflect.ValueOf(doc).Elem()
+
+	for i, seg := range segments {
+		last := i == len(segments)-1
+
+		if v.Kind() == reflect.Pointer {
+			switch {
+			case !v.IsNil():
+				v = v.Elem()
+			case create:
+				v.Set(reflect.New(v.Type().Elem()))
+				v = v.Elem()
+			default:
+				v = reflect.New(v.Type().Elem()).Elem()
+			}
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(v, seg)
+			if !ok {
+				return fmt.Errorf("unknown field %q", strings.Join(segments[:i+1], "."))
+			}
+			if last {
+				if field.Kind() == reflect.Map {
+					return fmt.Errorf("%s is a map, address one of its keys instead (e.g. %s.<key>)", path, path)
+				}
+				return fn(field, "")
+			}
+			v = field
+		case reflect.Map:
+			if !last {
+				return fmt.Errorf("%s cannot be nested", strings.Join(segments[:i+1], "."))
+			}
+			return fn(v, seg)
+		default:
+			return fmt.Errorf("%s is not a group of fields", strings.Join(segments[:i], "."))
+		}
+	}
+
+	return nil
+}
+
+// fieldByTag finds the struct field whose firestore tag matches name
+func fieldByTag(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if tag, _ := firestoreTag(sf); tag == name {
+			return v.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// coerce converts a string into a value of type t
+func coerce(t reflect.Type, s string) (reflect.Value, error) {
+	if t == timeType {
+		ts, err := ParseTime(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(ts), nil
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, fmt.Errorf("%q is not a boolean", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("%q is not an integer", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("%q is not a non-negative integer", s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("%q is not a number", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			return v, fmt.Errorf("unsupported list type %s", t)
+		}
+		list := reflect.MakeSlice(t, 0, 0)
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = reflect.Append(list, reflect.ValueOf(item).Convert(t.Elem()))
+			}
+		}
+		v.Set(list)
+	default:
+		return v, fmt.Errorf("unsupported field type %s", t)
+	}
+
+	return v, nil
+}
+
+// ParseTime parses an RFC 3339 timestamp, a plain date (YYYY-MM-DD) or the word "now"
+func ParseTime(s string) (time.Time, error) {
+	if strings.EqualFold(s, "now") {
+		return time.Now().UTC(), nil
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a timestamp (expected RFC 3339, YYYY-MM-DD or \"now\")", s)
+}
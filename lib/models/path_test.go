@@ -0,0 +1,139 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetField(t *testing.T) {
+	tests := []struct {
+		path, value string
+		check       func(m *Mod) bool
+		err         string
+	}{
+		{"name", "Better Storage", func(m *Mod) bool { return m.Name == "Better Storage" }, ""},
+		{"files.pak", "https://example.com/a.pak", func(m *Mod) bool { return m.Files["pak"] == "https://example.com/a.pak" }, ""},
+		{"release.tag", "v1.2", func(m *Mod) bool { return m.Release != nil && m.Release.Tag == "v1.2" }, ""},
+		{"release.assets.zip", "*.zip", func(m *Mod) bool { return m.Release != nil && m.Release.Assets["zip"] == "*.zip" }, ""},
+		{"moderation.hidden", "true", func(m *Mod) bool { return m.Moderation.Hidden }, ""},
+		{"moderation.moderated_at", "2025-03-04", func(m *Mod) bool {
+			return m.Moderation.ModeratedAt.Equal(time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC))
+		}, ""},
+		{"moderation.hidden", "maybe", nil, `"maybe" is not a boolean`},
+		{"bogus", "x", nil, `unknown field "bogus"`},
+		{"release.bogus", "x", nil, `unknown field "release.bogus"`},
+		{"files", "x", nil, "files is a map"},
+		{"files.pak.extra", "x", nil, "files.pak cannot be nested"},
+		{"name.first", "x", nil, "name is not a group of fields"},
+		{"moderation", "x", nil, "moderation is a group of fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+"="+tt.value, func(t *testing.T) {
+			m := &Mod{}
+			err := SetField(m, tt.path, tt.value)
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("SetField() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetField() error = %v", err)
+			}
+			if !tt.check(m) {
+				t.Errorf("SetField(%q, %q) left %+v", tt.path, tt.value, m)
+			}
+		})
+	}
+}
+
+func TestUnsetField(t *testing.T) {
+	tests := []struct {
+		name  string
+		mod   Mod
+		path  string
+		check func(m *Mod) bool
+		err   string
+	}{
+		{"plain field", Mod{Description: "old"}, "description", func(m *Mod) bool { return m.Description == "" }, ""},
+		{"map key", Mod{Files: map[string]string{"pak": "a", "zip": "b"}}, "files.pak",
+			func(m *Mod) bool { return reflect.DeepEqual(m.Files, map[string]string{"zip": "b"}) }, ""},
+		{"key of a nil map", Mod{}, "files.pak", func(m *Mod) bool { return m.Files == nil }, ""},
+		{"nested field", Mod{Release: &Release{Tag: "v1"}}, "release.tag", func(m *Mod) bool { return m.Release.Tag == "" }, ""},
+		{"whole group", Mod{Release: &Release{Tag: "v1"}}, "release", func(m *Mod) bool { return m.Release == nil }, ""},
+		{"field of a nil group", Mod{}, "release.tag", func(m *Mod) bool { return m.Release == nil }, ""},
+		{"map key of a nil group", Mod{}, "release.assets.zip", func(m *Mod) bool { return m.Release == nil }, ""},
+		{"unknown field of a nil group", Mod{}, "release.bogus", nil, `unknown field "release.bogus"`},
+		{"unknown field", Mod{}, "bogus", nil, `unknown field "bogus"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.mod
+			err := UnsetField(&m, tt.path)
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("UnsetField() error = %v, want %q", err, tt.err)
+				}
+				if m.Release != nil {
+					t.Errorf("UnsetField() allocated release: %+v", m.Release)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnsetField() error = %v", err)
+			}
+			if !tt.check(&m) {
+				t.Errorf("UnsetField(%q) left %+v", tt.path, m)
+			}
+		})
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	tests := []struct {
+		t     reflect.Type
+		value string
+		want  any
+		err   bool
+	}{
+		{reflect.TypeOf(""), "text", "text", false},
+		{reflect.TypeOf(true), "false", false, false},
+		{reflect.TypeOf(true), "yes", nil, true},
+		{reflect.TypeOf(int64(0)), "-42", int64(-42), false},
+		{reflect.TypeOf(int8(0)), "300", nil, true},
+		{reflect.TypeOf(uint(0)), "7", uint(7), false},
+		{reflect.TypeOf(uint(0)), "-7", nil, true},
+		{reflect.TypeOf(0.0), "2.5", 2.5, false},
+		{reflect.TypeOf(0.0), "abc", nil, true},
+		{reflect.TypeOf([]string{}), " a, b ,,c ", []string{"a", "b", "c"}, false},
+		{reflect.TypeOf([]string{}), "", []string{}, false},
+		{reflect.TypeOf([]int{}), "1,2", nil, true},
+		{timeType, "2025-01-02T03:04:05Z", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{timeType, "2025-01-02", time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		{timeType, "yesterday", nil, true},
+		{reflect.TypeOf(map[string]string{}), "x", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := coerce(tt.t, tt.value)
+		if tt.err {
+			if err == nil {
+				t.Errorf("coerce(%s, %q) = %v, want an error", tt.t, tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("coerce(%s, %q) error = %v", tt.t, tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Interface(), tt.want) {
+			t.Errorf("coerce(%s, %q) = %#v, want %#v", tt.t, tt.value, got.Interface(), tt.want)
+		}
+	}
+}
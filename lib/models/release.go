@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// LatestRelease refers to the newest non-draft, non-prerelease release of a repository
+const LatestRelease = "latest"
+
+// Release references GitHub release assets that sync resolves to download URLs.
+// Assets maps a file type (as used in files) to a glob matching exactly one asset name, e.g. {"exmodz": "*.EXMODZ"}.
+type Release struct {
+	Repo   string            `firestore:"repo,omitempty" json:"repo,omitempty" yaml:"repo,omitempty"`
+	Tag    string            `firestore:"tag" json:"tag" yaml:"tag"`
+	Assets map[string]string `firestore:"assets" json:"assets" yaml:"assets"`
+}
+
+// ResolvedRelease records what a Release reference pointed to at the last sync
+type ResolvedRelease struct {
+	Tag         string            `firestore:"tag" json:"tag" yaml:"tag"`
+	Version     string            `firestore:"version" json:"version" yaml:"version"`
+	PublishedAt time.Time         `firestore:"published_at" json:"published_at" yaml:"published_at"`
+	Assets      map[string]string `firestore:"assets" json:"assets" yaml:"assets"`
+}
+
+// Validate reports every problem with the release reference as a single joined error
+func (r *Release) Validate() error {
+	var errs []error
+
+	if strings.TrimSpace(r.Tag) == "" {
+		errs = append(errs, fmt.Errorf("release.tag is required (use %q for the newest release)", LatestRelease))
+	}
+	if len(r.Assets) == 0 {
+		errs = append(errs, errors.New("release.assets requires at least one asset pattern"))
+	}
+	for fileType, pattern := range r.Assets {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("release.assets.%s: invalid pattern %q", fileType, pattern))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// VersionFromTag derives a version from a release tag by dropping a leading "v" (e.g. "v1.2.0" becomes "1.2.0")
+func VersionFromTag(tag string) string {
+	if len(tag) > 1 && (tag[0] == 'v' || tag[0] == 'V') && tag[1] >= '0' && tag[1] <= '9' {
+		return tag[1:]
+	}
+
+	return tag
+}
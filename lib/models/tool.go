@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Tool represents a document in the tools collection
+type Tool struct {
+	Name            string            `firestore:"name" json:"name" yaml:"name"`
+	Author          string            `firestore:"author" json:"author" yaml:"author"`
+	Version         string            `firestore:"version" json:"version" yaml:"version"`
+	Compatibility   string            `firestore:"compatibility,omitempty" json:"compatibility,omitempty" yaml:"compatibility,omitempty"`
+	Description     string            `firestore:"description,omitempty" json:"description,omitempty" yaml:"description,omitempty"`
+	LongDescription string            `firestore:"long_description,omitempty" json:"long_description,omitempty" yaml:"long_description,omitempty"`
+	Files           map[string]string `firestore:"files" json:"files" yaml:"files"`
+	ImageURL        string            `firestore:"imageURL,omitempty" json:"imageURL,omitempty" yaml:"imageURL,omitempty"`
+	ReadmeURL       string            `firestore:"readmeURL,omitempty" json:"readmeURL,omitempty" yaml:"readmeURL,omitempty"`
+	CreatedAt       time.Time         `firestore:"created_at,omitempty" json:"created_at,omitzero" yaml:"created_at,omitempty"`
+	UpdatedAt       time.Time         `firestore:"updated_at,omitempty" json:"updated_at,omitzero" yaml:"updated_at,omitempty"`
+	Moderation      Moderation        `firestore:"moderation" json:"moderation,omitzero" yaml:"moderation,omitempty"`
+}
+
+// Validate reports every problem with the tool as a single joined error
+func (t *Tool) Validate() error {
+	return validateEntry(t.Name, t.Author, t.Version, t.Files, t.ImageURL, t.ReadmeURL)
+}
+
+// Key identifies the tool across syncs by its author and name
+func (t *Tool) Key() string {
+	return strings.ToLower(t.Author + "/" + t.Name)
+}
+
+// Moderated returns the moderation state of the tool
+func (t *Tool) Moderated() *Moderation {
+	return &t.Moderation
+}
+
+// Timestamps returns pointers to the created and updated times of the tool
+func (t *Tool) Timestamps() (createdAt, updatedAt *time.Time) {
+	return &t.CreatedAt, &t.UpdatedAt
+}
@@ -0,0 +1,26 @@
+package operator
+
+import (
+	"os"
+	"os/user"
+
+	"github.com/spf13/viper"
+)
+
+// Name returns the identity of the person running pdt.
+// The "operator" config key takes precedence, otherwise user@host of the current OS user is used.
+func Name() string {
+	if name := viper.GetString("operator"); name != "" {
+		return name
+	}
+
+	name := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		name += "@" + host
+	}
+
+	return name
+}
@@ -0,0 +1,228 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/github"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+)
+
+// DiscoverOptions controls how Candidates searches GitHub
+type DiscoverOptions struct {
+	// Topics are repository topics to search for (e.g. "icarus-mod")
+	Topics []string
+	// CodeSearch also searches for repositories containing modinfo.json or toolinfo.json (requires github.token)
+	CodeSearch bool
+	// Limit caps the results of each search
+	Limit int
+}
+
+// Candidate is a repository that may hold mods or tools but is not listed in meta/repos
+type Candidate struct {
+	Repo     github.Repository
+	Matched  []string
+	Previews []Preview
+}
+
+// Preview summarizes an info file found in a candidate repository, as sync would read it
+type Preview struct {
+	Kind    string
+	File    string
+	Entries int
+	Invalid []string
+	Err     error
+}
+
+// Candidates searches GitHub for repositories that are not yet listed in meta/repos, as active or inactive, and that
+// no hidden or blocked mod or tool was published from. It previews the modinfo and toolinfo files they contain,
+// most starred first.
+func Candidates(ctx context.Context, opts DiscoverOptions) ([]Candidate, error) {
+	ctx, span := tracing.Start(ctx, "sync.Candidates")
+	defer span.End()
+
+	gh, err := github.New()
+	if err != nil {
+		return nil, err
+	}
+
+	known, err := knownRepos(ctx, gh)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*Candidate)
+	add := func(repo github.Repository, matched string) {
+		key := strings.ToLower(repo.FullName)
+		if known[key] {
+			return
+		}
+		if c, ok := found[key]; ok {
+			c.Matched = append(c.Matched, matched)
+			return
+		}
+		found[key] = &Candidate{Repo: repo, Matched: []string{matched}}
+	}
+
+	for _, topic := range opts.Topics {
+		results, err := gh.SearchRepositories(ctx, "topic:"+topic, opts.Limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range results {
+			add(repo, "topic:"+topic)
+		}
+	}
+
+	if opts.CodeSearch {
+		if gh.Token == "" {
+			logger.For(subsystem).Warn("Skipping the code search, which requires github.token")
+		} else if err := searchInfoFiles(ctx, gh, opts.Limit, add); err != nil {
+			return nil, err
+		}
+	}
+
+	candidates := make([]Candidate, 0, len(found))
+	for _, c := range found {
+		c.Previews = preview(ctx, gh, c.Repo.FullName)
+		candidates = append(candidates, *c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Repo.StargazersCount != candidates[j].Repo.StargazersCount {
+			return candidates[i].Repo.StargazersCount > candidates[j].Repo.StargazersCount
+		}
+		return strings.ToLower(candidates[i].Repo.FullName) < strings.ToLower(candidates[j].Repo.FullName)
+	})
+
+	return candidates, nil
+}
+
+// knownRepos returns the lower-cased names of the repositories discover leaves out: the active and inactive ones
+// in meta/repos, and the ones hidden or blocked mods and tools were published from
+func knownRepos(ctx context.Context, gh *github.Client) (map[string]bool, error) {
+	active, inactive, err := firestore.RepoLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(active)+len(inactive))
+	for _, repo := range append(active, inactive...) {
+		if name, err := github.RepoName(repo); err == nil {
+			known[strings.ToLower(name)] = true
+		}
+	}
+
+	for _, kind := range models.Kinds {
+		entries, err := firestore.Entries(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if m := e.Doc.Moderated(); !m.Hidden && !m.Blocked {
+				continue
+			}
+			for _, name := range entryRepos(gh, e.Doc) {
+				known[strings.ToLower(name)] = true
+			}
+		}
+	}
+
+	return known, nil
+}
+
+// entryRepos returns the GitHub repositories a mod or tool is published from, judging by its release and file URLs
+func entryRepos(gh *github.Client, doc models.Document) []string {
+	var files map[string]string
+	var repos []string
+
+	switch doc := doc.(type) {
+	case *models.Mod:
+		files = doc.Files
+		if doc.Release != nil && doc.Release.Repo != "" {
+			if name, err := github.RepoName(doc.Release.Repo); err == nil {
+				repos = append(repos, name)
+			}
+		}
+	case *models.Tool:
+		files = doc.Files
+	}
+
+	for _, url := range files {
+		if name, ok := gh.RepoOf(url); ok && !slices.Contains(repos, name) {
+			repos = append(repos, name)
+		}
+	}
+	slices.Sort(repos)
+
+	return repos
+}
+
+// searchInfoFiles finds repositories containing a modinfo or toolinfo file.
+// Code search only returns a summary of each repository, so the full details are fetched separately.
+func searchInfoFiles(ctx context.Context, gh *github.Client, limit int, add func(github.Repository, string)) error {
+	for _, kind := range models.Kinds {
+		file := sources[kind].file
+
+		results, err := gh.SearchCode(ctx, "filename:"+file, limit)
+		if err != nil {
+			return err
+		}
+		for _, summary := range results {
+			repo, err := gh.Repository(ctx, summary.FullName)
+			if err != nil {
+				logger.For(subsystem).Warn(fmt.Sprintf("Skipping %s: %v", summary.FullName, err))
+				continue
+			}
+			add(*repo, file)
+		}
+	}
+
+	return nil
+}
+
+// preview reads the info files of a repository the way sync would, without writing anything
+func preview(ctx context.Context, gh *github.Client, repo string) []Preview {
+	var previews []Preview
+
+	for _, kind := range models.Kinds {
+		file := sources[kind].file
+
+		url, err := gh.RawURL(repo, file)
+		if err != nil {
+			continue
+		}
+		if ok, err := gh.Exists(ctx, url); err != nil || !ok {
+			continue
+		}
+
+		p := Preview{Kind: kind, File: file}
+		docs, err := fetchInfo(ctx, gh, kind, url)
+		if err != nil {
+			p.Err = err
+			previews = append(previews, p)
+			continue
+		}
+
+		p.Entries = len(docs)
+		for _, doc := range docs {
+			if mod, ok := doc.(*models.Mod); ok && mod.Release != nil && mod.Release.Validate() == nil {
+				if err := resolveRelease(ctx, gh, mod, url); err != nil {
+					p.Invalid = append(p.Invalid, fmt.Sprintf("%s: %v", doc.Key(), err))
+					continue
+				}
+			}
+			if err := doc.Validate(); err != nil {
+				p.Invalid = append(p.Invalid, fmt.Sprintf("%s: %v", doc.Key(), err))
+			}
+		}
+		previews = append(previews, p)
+	}
+
+	return previews
+}
@@ -0,0 +1,40 @@
+package syncer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/github"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+)
+
+func TestEntryRepos(t *testing.T) {
+	gh, err := github.NewClient("", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		doc  models.Document
+		want []string
+	}{
+		{"mod with release and files", &models.Mod{
+			Release: &models.Release{Repo: "https://github.com/jane/mods"},
+			Files: map[string]string{
+				"pak": "https://github.com/jane/mods/releases/download/v1/mod.pak",
+				"zip": "https://raw.githubusercontent.com/jane/extras/main/mod.zip",
+			},
+		}, []string{"jane/extras", "jane/mods"}},
+		{"tool", &models.Tool{Files: map[string]string{"zip": "https://github.com/joe/tool/releases/download/v2/tool.zip"}}, []string{"joe/tool"}},
+		{"files hosted elsewhere", &models.Mod{Files: map[string]string{"pak": "https://example.com/mod.pak"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entryRepos(gh, tt.doc); !slices.Equal(got, tt.want) {
+				t.Errorf("entryRepos() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/linkcheck"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+)
+
+// probeFiles records the size and content type the server reports for each file of a mod, flagging
+// HTML pages and empty files. Files that cannot be reached keep what the current document recorded,
+// and files whose URL did not change keep their verified hash.
+func probeFiles(ctx context.Context, checker *linkcheck.Checker, mod *models.Mod, current models.Document) {
+	var previous map[string]models.FileInfo
+	var previousURLs map[string]string
+	if cur, ok := current.(*models.Mod); ok {
+		previous, previousURLs = cur.FileInfo, cur.Files
+	}
+
+	results := checker.CheckAll(ctx, slices.Collect(maps.Values(mod.Files)))
+
+	info := make(map[string]models.FileInfo, len(mod.Files))
+	for fileType, url := range mod.Files {
+		r := results[url]
+		if r.Broken() {
+			logger.For(subsystem).Warn(fmt.Sprintf("Unable to read files.%s of mod %q: %s", fileType, mod.Key(), r.Problem(0)))
+			if fi, ok := previous[fileType]; ok {
+				info[fileType] = fi
+			}
+			continue
+		}
+
+		fi := models.FileInfo{Size: r.ContentLength, ContentType: r.ContentType}
+		if prev, ok := previous[fileType]; ok && previousURLs[fileType] == url {
+			fi.SHA256, fi.VerifiedAt, fi.VerifiedVersion, fi.ChangedWithoutBump = prev.SHA256, prev.VerifiedAt, prev.VerifiedVersion, prev.ChangedWithoutBump
+		}
+		fi.Problem = models.FileProblem(fileType, fi.Size, fi.ContentType)
+		if fi.Problem != "" {
+			logger.For(subsystem).Warn(fmt.Sprintf("files.%s of mod %q: %s (%s)", fileType, mod.Key(), fi.Problem, url))
+		}
+		info[fileType] = fi
+	}
+
+	mod.FileInfo = nil
+	if len(info) > 0 {
+		mod.FileInfo = info
+	}
+}
@@ -0,0 +1,230 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/github"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Repository states reported by CheckRepos
+const (
+	RepoOK          = "ok"
+	RepoRenamed     = "renamed"
+	RepoTransferred = "transferred"
+	RepoArchived    = "archived"
+	RepoDisabled    = "disabled"
+	RepoDeleted     = "deleted"
+	RepoInvalid     = "invalid"
+	RepoError       = "error"
+)
+
+// RepoHealth is the state of one repository listed in meta/repos
+type RepoHealth struct {
+	// Repo is the entry as stored in meta/repos
+	Repo   string
+	Status string
+	// Canonical is the current owner/name of a renamed or transferred repository
+	Canonical string
+	Err       error
+}
+
+// Moved reports whether the repository now lives at a different owner/name
+func (h RepoHealth) Moved() bool {
+	return h.Status == RepoRenamed || h.Status == RepoTransferred
+}
+
+// Inactive reports whether the repository can no longer be synced from
+func (h RepoHealth) Inactive() bool {
+	return h.Status == RepoArchived || h.Status == RepoDisabled || h.Status == RepoDeleted
+}
+
+// String explains the state of the repository
+func (h RepoHealth) String() string {
+	switch {
+	case h.Moved():
+		return fmt.Sprintf("%s was %s to %s", h.Repo, h.Status, h.Canonical)
+	case h.Err != nil:
+		return fmt.Sprintf("%s: %s (%v)", h.Repo, h.Status, h.Err)
+	default:
+		return fmt.Sprintf("%s is %s", h.Repo, h.Status)
+	}
+}
+
+// CheckRepos looks up every active repository in meta/repos on GitHub and reports
+// the ones that were renamed, transferred, archived, disabled or deleted
+func CheckRepos(ctx context.Context) ([]RepoHealth, error) {
+	gh, err := github.New()
+	if err != nil {
+		return nil, err
+	}
+
+	repos, _, err := firestore.RepoLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkRepos(ctx, gh, repos), nil
+}
+
+func checkRepos(ctx context.Context, gh *github.Client, repos []string) []RepoHealth {
+	health := make([]RepoHealth, 0, len(repos))
+	for _, repo := range repos {
+		h := checkRepo(ctx, gh, repo)
+		if h.Status != RepoOK {
+			metrics.Inc(metrics.RepoProblems, "status", h.Status)
+		}
+		health = append(health, h)
+	}
+
+	return health
+}
+
+func checkRepo(ctx context.Context, gh *github.Client, repo string) (h RepoHealth) {
+	ctx, span := tracing.Start(ctx, "sync.checkRepo", attribute.String("repo", repo))
+	defer func() {
+		span.SetAttributes(attribute.String("status", h.Status))
+		tracing.End(span, h.Err)
+	}()
+
+	h = RepoHealth{Repo: repo, Status: RepoOK}
+
+	name, err := github.RepoName(repo)
+	if err != nil {
+		h.Status, h.Err = RepoInvalid, err
+		return h
+	}
+
+	// GitHub answers requests for a renamed or transferred repository with a redirect to its new location
+	current, err := gh.Repository(ctx, name)
+	var ghErr *github.Error
+	switch {
+	case errors.As(err, &ghErr) && (ghErr.StatusCode == http.StatusNotFound || ghErr.StatusCode == http.StatusUnavailableForLegalReasons):
+		h.Status = RepoDeleted
+		return h
+	case errors.As(err, &ghErr) && ghErr.StatusCode == http.StatusForbidden && strings.Contains(strings.ToLower(ghErr.Message), "disabled"):
+		h.Status = RepoDisabled
+		return h
+	case err != nil:
+		h.Status, h.Err = RepoError, err
+		return h
+	}
+
+	oldOwner, _, _ := strings.Cut(name, "/")
+	newOwner, _, _ := strings.Cut(current.FullName, "/")
+	switch {
+	case !strings.EqualFold(oldOwner, newOwner):
+		h.Status, h.Canonical = RepoTransferred, current.FullName
+	case !strings.EqualFold(name, current.FullName):
+		h.Status, h.Canonical = RepoRenamed, current.FullName
+	case current.Disabled:
+		h.Status = RepoDisabled
+	case current.Archived:
+		h.Status = RepoArchived
+	}
+
+	return h
+}
+
+// FixRepos rewrites moved repositories to their new location, in meta/repos and in the modinfo and toolinfo lists,
+// and moves archived, disabled and deleted repositories to the inactive list, dropping their modinfo and toolinfo URLs
+// so sync no longer reads them. Every list is updated in one transaction. It returns the number of repositories changed.
+func FixRepos(ctx context.Context, health []RepoHealth) (int, error) {
+	gh, err := github.New()
+	if err != nil {
+		return 0, err
+	}
+
+	keys := []string{firestore.MetaRepos}
+	for _, kind := range models.Kinds {
+		keys = append(keys, sources[kind].meta)
+	}
+
+	changed := 0
+	err = firestore.UpdateMetaLists(ctx, keys, func(docs map[string]*firestore.MetaLists) error {
+		repos := docs[firestore.MetaRepos]
+
+		// Info file URLs are rewritten from the old raw prefix to the new one
+		renames := make(map[string]string)
+		changed = 0
+		for _, h := range health {
+			i := slices.Index(repos.List, h.Repo)
+			if i < 0 {
+				continue
+			}
+
+			switch {
+			case h.Moved():
+				oldName, _ := github.RepoName(h.Repo)
+				renames[oldName] = h.Canonical
+				repos.List[i] = canonicalEntry(h.Repo, h.Canonical)
+				logger.For(subsystem).Info(fmt.Sprintf("Repository %s was %s to %s", h.Repo, h.Status, h.Canonical))
+			case h.Inactive():
+				repos.List = slices.Delete(repos.List, i, i+1)
+				repos.Inactive = append(repos.Inactive, h.Repo)
+				logger.For(subsystem).Info(fmt.Sprintf("Repository %s is %s; marking it inactive", h.Repo, h.Status))
+			default:
+				continue
+			}
+			changed++
+		}
+
+		for _, kind := range models.Kinds {
+			meta := sources[kind].meta
+			docs[meta].List = fixInfoURLs(gh, docs[meta].List, renames, repos.Inactive)
+		}
+
+		return nil
+	})
+
+	return changed, err
+}
+
+// fixInfoURLs points the modinfo or toolinfo URLs of moved repositories at their new location
+// and drops the URLs of inactive repositories
+func fixInfoURLs(gh *github.Client, urls []string, renames map[string]string, inactive []string) []string {
+	fixed := make([]string, 0, len(urls))
+	for _, url := range urls {
+		repo, ok := gh.RepoOf(url)
+		if !ok {
+			fixed = append(fixed, url)
+			continue
+		}
+
+		if slices.ContainsFunc(inactive, func(entry string) bool {
+			name, err := github.RepoName(entry)
+			return err == nil && strings.EqualFold(name, repo)
+		}) {
+			logger.For(subsystem).Info(fmt.Sprintf("Dropping %s of inactive repository %s", url, repo))
+			continue
+		}
+
+		for oldName, newName := range renames {
+			if strings.EqualFold(repo, oldName) {
+				url = strings.Replace(url, repo, newName, 1)
+			}
+		}
+		fixed = append(fixed, url)
+	}
+
+	return fixed
+}
+
+// canonicalEntry formats the new location of a repository the same way as the stored entry (URL or owner/name)
+func canonicalEntry(stored, canonical string) string {
+	if strings.Contains(stored, "github.com") {
+		return "https://github.com/" + canonical
+	}
+
+	return canonical
+}
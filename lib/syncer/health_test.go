@@ -0,0 +1,34 @@
+package syncer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/github"
+)
+
+func TestFixInfoURLs(t *testing.T) {
+	gh, err := github.NewClient("", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	urls := []string{
+		"https://raw.githubusercontent.com/old/mods/main/modinfo.json",
+		"https://raw.githubusercontent.com/Gone/mods/main/modinfo.json",
+		"https://raw.githubusercontent.com/kept/mods/main/modinfo.json",
+		"https://example.com/modinfo.json",
+	}
+	renames := map[string]string{"old/mods": "new/mods"}
+	inactive := []string{"https://github.com/gone/mods"}
+
+	got := fixInfoURLs(gh, urls, renames, inactive)
+	want := []string{
+		"https://raw.githubusercontent.com/new/mods/main/modinfo.json",
+		"https://raw.githubusercontent.com/kept/mods/main/modinfo.json",
+		"https://example.com/modinfo.json",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("fixInfoURLs() = %q, want %q", got, want)
+	}
+}
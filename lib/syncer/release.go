@@ -0,0 +1,87 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/github"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// resolveRelease points the files of a mod that references a GitHub release at the matching release assets
+// and records what the reference resolved to. The repository defaults to the one hosting the modinfo file.
+func resolveRelease(ctx context.Context, gh *github.Client, mod *models.Mod, infoURL string) (err error) {
+	ref := mod.Release
+
+	repo := ref.Repo
+	if repo == "" {
+		var ok bool
+		if repo, ok = gh.RepoOf(infoURL); !ok {
+			return fmt.Errorf("release.repo is required because %s is not hosted on GitHub", infoURL)
+		}
+	}
+
+	ctx, span := tracing.Start(ctx, "sync.resolveRelease", attribute.String("repo", repo), attribute.String("tag", ref.Tag))
+	defer func() { tracing.End(span, err) }()
+
+	release, err := gh.Release(ctx, repo, ref.Tag)
+	if err != nil {
+		return fmt.Errorf("release %q of %s: %w", ref.Tag, repo, err)
+	}
+
+	resolved := &models.ResolvedRelease{
+		Tag:         release.TagName,
+		Version:     models.VersionFromTag(release.TagName),
+		PublishedAt: release.PublishedAt.UTC(),
+		Assets:      make(map[string]string, len(ref.Assets)),
+	}
+	for fileType, pattern := range ref.Assets {
+		asset, err := matchAsset(release.Assets, pattern)
+		if err != nil {
+			return fmt.Errorf("release %s of %s: release.assets.%s: %w", release.TagName, repo, fileType, err)
+		}
+		resolved.Assets[fileType] = asset.BrowserDownloadURL
+	}
+
+	if mod.Files == nil {
+		mod.Files = make(map[string]string, len(resolved.Assets))
+	}
+	for fileType, url := range resolved.Assets {
+		mod.Files[fileType] = url
+	}
+	if mod.Version == "" {
+		mod.Version = resolved.Version
+	}
+	mod.ResolvedRelease = resolved
+
+	logger.For(subsystem).Debug(fmt.Sprintf("Resolved release %q of %s to %s", ref.Tag, repo, release.TagName))
+	return nil
+}
+
+// matchAsset returns the single asset whose name matches pattern, ignoring case
+func matchAsset(assets []github.Asset, pattern string) (github.Asset, error) {
+	var matches []github.Asset
+	for _, asset := range assets {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(asset.Name)); ok {
+			matches = append(matches, asset)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return github.Asset{}, fmt.Errorf("no asset matches %q", pattern)
+	default:
+		names := make([]string, len(matches))
+		for i, m := range matches {
+			names[i] = m.Name
+		}
+		return github.Asset{}, fmt.Errorf("%q matches several assets (%s)", pattern, strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,84 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/github"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// discover looks for modinfo and toolinfo files in every configured repository
+// and adds any that are found to the corresponding meta lists
+func discover(ctx context.Context, gh *github.Client) error {
+	repos, err := firestore.MetaList(ctx, firestore.MetaRepos)
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range models.Kinds {
+		source := sources[kind]
+
+		list, err := firestore.MetaList(ctx, source.meta)
+		if err != nil {
+			return err
+		}
+
+		var added []string
+		for _, repo := range repos {
+			url, err := gh.RawURL(repo, source.file)
+			if err != nil {
+				logger.For(subsystem).Warn(fmt.Sprintf("Skipping repository %q: %v", repo, err))
+				continue
+			}
+			if slices.Contains(list, url) {
+				continue
+			}
+
+			metrics.Inc(metrics.ReposProcessed, "stage", "discover", "kind", kind)
+			repoCtx, span := tracing.Start(ctx, "sync.discover", attribute.String("repo", repo), attribute.String("url", url))
+			found, err := gh.Exists(repoCtx, url)
+			span.SetAttributes(attribute.Bool("found", found))
+			tracing.End(span, err)
+			if err != nil {
+				metrics.Error(subsystem, err)
+				logger.For(subsystem).Warn(fmt.Sprintf("Unable to reach %s: %v", url, err))
+				continue
+			}
+
+			if found {
+				logger.For(subsystem).Info(fmt.Sprintf("Found %s in %s", source.file, repo))
+				added = append(added, url)
+			}
+		}
+
+		if len(added) == 0 {
+			continue
+		}
+		if viper.GetBool("dryrun") {
+			logger.For(subsystem).Info(fmt.Sprintf("Dry run: would add %d %s URL(s)", len(added), source.meta))
+			continue
+		}
+		// The list is re-read in the transaction so URLs added since it was fetched above are kept
+		err = firestore.UpdateMetaLists(ctx, []string{source.meta}, func(docs map[string]*firestore.MetaLists) error {
+			for _, url := range added {
+				if !slices.Contains(docs[source.meta].List, url) {
+					docs[source.meta].List = append(docs[source.meta].List, url)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/github"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/linkcheck"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// subsystem names this package in log records
+const subsystem = "sync"
+
+// Result summarizes the outcome of syncing one kind of document
+type Result struct {
+	Kind      string
+	Created   int
+	Updated   int
+	Unchanged int
+	Blocked   int
+	Invalid   int
+	Errors    []error
+}
+
+// sources maps each document kind to the meta document listing its info files and the key holding the entries
+var sources = map[string]struct {
+	meta    string
+	file    string
+	rootKey string
+}{
+	models.KindMod:  {meta: firestore.MetaModinfo, file: "modinfo.json", rootKey: "mods"},
+	models.KindTool: {meta: firestore.MetaToolinfo, file: "toolinfo.json", rootKey: "tools"},
+}
+
+// Run discovers info files from the configured repositories and then rewrites
+// every mod and tool document from its modinfo or toolinfo source.
+// Moderation fields are preserved and blocked entries are never re-created.
+// When github.token is set, it also returns the repositories that were moved, archived or deleted.
+func Run(ctx context.Context) ([]Result, []RepoHealth, error) {
+	ctx, span := tracing.Start(ctx, "sync.Run")
+	defer span.End()
+
+	gh, err := github.New()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The health pass costs an API request per repository, more than the anonymous rate limit allows for
+	var problems []RepoHealth
+	if gh.Token == "" {
+		logger.For(subsystem).Info("Skipping the repository health pass without github.token")
+	} else {
+		repos, _, err := firestore.RepoLists(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, h := range checkRepos(ctx, gh, repos) {
+			if h.Status != RepoOK {
+				problems = append(problems, h)
+			}
+		}
+	}
+
+	if err := discover(ctx, gh); err != nil {
+		return nil, problems, err
+	}
+
+	results := make([]Result, 0, len(models.Kinds))
+	for _, kind := range models.Kinds {
+		result, err := syncKind(ctx, gh, kind)
+		if err != nil {
+			return results, problems, err
+		}
+		results = append(results, result)
+	}
+
+	return results, problems, nil
+}
+
+// syncKind rewrites every document of one kind from its info sources
+func syncKind(ctx context.Context, gh *github.Client, kind string) (Result, error) {
+	ctx, span := tracing.Start(ctx, "sync.syncKind", attribute.String("kind", kind))
+	defer span.End()
+
+	result := Result{Kind: kind}
+	dryrun := viper.GetBool("dryrun")
+
+	existing, err := firestore.Entries(ctx, kind)
+	if err != nil {
+		return result, err
+	}
+	byKey := make(map[string]firestore.Entry, len(existing))
+	for _, e := range existing {
+		byKey[e.Doc.Key()] = e
+	}
+
+	urls, err := firestore.MetaList(ctx, sources[kind].meta)
+	if err != nil {
+		return result, err
+	}
+
+	checker := linkcheck.New(linkcheck.Options{})
+	seen := make(map[string]string)
+	for _, url := range urls {
+		metrics.Inc(metrics.ReposProcessed, "stage", "sync", "kind", kind)
+		docs, err := fetchInfo(ctx, gh, kind, url)
+		if err != nil {
+			metrics.Error(subsystem, err)
+			logger.For(subsystem).Error(fmt.Sprintf("Unable to read %s: %v", url, err))
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", url, err))
+			continue
+		}
+
+		for _, doc := range docs {
+			key := doc.Key()
+			if mod, ok := doc.(*models.Mod); ok && mod.Release != nil && mod.Release.Validate() == nil {
+				if err := resolveRelease(ctx, gh, mod, url); err != nil {
+					metrics.Error(subsystem, err)
+					logger.For(subsystem).Error(fmt.Sprintf("Unable to resolve the release of %s %q from %s: %v", kind, key, url, err))
+					result.Errors = append(result.Errors, fmt.Errorf("%s %q: %w", kind, key, err))
+					continue
+				}
+			}
+			if err := doc.Validate(); err != nil {
+				logger.For(subsystem).Warn(fmt.Sprintf("Skipping invalid %s %q from %s: %v", kind, key, url, err))
+				result.Invalid++
+				continue
+			}
+			if other, ok := seen[key]; ok {
+				logger.For(subsystem).Warn(fmt.Sprintf("Skipping duplicate %s %q from %s (already read from %s)", kind, key, url, other))
+				result.Invalid++
+				continue
+			}
+			seen[key] = url
+
+			current, exists := byKey[key]
+			if mod, ok := doc.(*models.Mod); ok && !(exists && current.Doc.Moderated().Blocked) {
+				probeFiles(ctx, checker, mod, current.Doc)
+			}
+
+			now := time.Now().UTC()
+			createdAt, updatedAt := doc.Timestamps()
+
+			if !exists {
+				*createdAt, *updatedAt = now, now
+				if dryrun {
+					logger.For(subsystem).Info(fmt.Sprintf("Dry run: would create %s %q", kind, key))
+				} else if _, err := firestore.CreateEntry(ctx, kind, doc); err != nil {
+					result.Errors = append(result.Errors, fmt.Errorf("%s %q: %w", kind, key, err))
+					continue
+				}
+				result.Created++
+				continue
+			}
+
+			if current.Doc.Moderated().Blocked {
+				logger.For(subsystem).Info(fmt.Sprintf("Skipping blocked %s %q (%s)", kind, key, current.ID))
+				result.Blocked++
+				continue
+			}
+
+			// Fields owned by the database are carried over so the rewrite never clobbers them
+			*doc.Moderated() = *current.Doc.Moderated()
+			currentCreatedAt, currentUpdatedAt := current.Doc.Timestamps()
+			*createdAt, *updatedAt = *currentCreatedAt, *currentUpdatedAt
+			if models.Equal(current.Doc, doc) {
+				result.Unchanged++
+				continue
+			}
+
+			*updatedAt = now
+			if dryrun {
+				logger.For(subsystem).Info(fmt.Sprintf("Dry run: would update %s %q (%s)", kind, key, current.ID))
+			} else if err := firestore.UpdateEntry(ctx, kind, current.ID, current.Doc, doc, current.UpdateTime); err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("%s %q: %w", kind, key, err))
+				continue
+			}
+			result.Updated++
+		}
+	}
+
+	result.record()
+	return result, nil
+}
+
+// record adds the counts of a result to the sync metrics
+func (r Result) record() {
+	for name, n := range map[string]int{
+		"created":   r.Created,
+		"updated":   r.Updated,
+		"unchanged": r.Unchanged,
+		"blocked":   r.Blocked,
+		"invalid":   r.Invalid,
+		"error":     len(r.Errors),
+	} {
+		metrics.Add(metrics.SyncDocuments, float64(n), "kind", r.Kind, "result", name)
+	}
+}
+
+// fetchInfo reads a modinfo or toolinfo file and returns the documents it describes
+func fetchInfo(ctx context.Context, gh *github.Client, kind, url string) (docs []models.Document, err error) {
+	ctx, span := tracing.Start(ctx, "sync.fetchInfo", attribute.String("kind", kind), attribute.String("url", url))
+	defer func() { tracing.End(span, err) }()
+
+	var info map[string][]json.RawMessage
+	if _, err := gh.Get(ctx, url, &info); err != nil {
+		return nil, err
+	}
+
+	raw, ok := info[sources[kind].rootKey]
+	if !ok {
+		return nil, fmt.Errorf("missing %q key", sources[kind].rootKey)
+	}
+
+	docs = make([]models.Document, 0, len(raw))
+	for i, r := range raw {
+		doc, _ := models.New(kind)
+		if err := json.Unmarshal(r, doc); err != nil {
+			return nil, fmt.Errorf("%s %d: %w", kind, i, err)
+		}
+
+		// Moderation, timestamps, resolved releases and file info are owned by the database, never by the source
+		*doc.Moderated() = models.Moderation{}
+		if mod, ok := doc.(*models.Mod); ok {
+			mod.ResolvedRelease = nil
+			mod.FileInfo = nil
+		}
+		createdAt, updatedAt := doc.Timestamps()
+		*createdAt, *updatedAt = time.Time{}, time.Time{}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
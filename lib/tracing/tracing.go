@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace destinations accepted by Setup
+const (
+	Stdout     = "stdout"
+	FilePrefix = "file:"
+	OTLP       = "otlp"
+)
+
+// tracerName identifies the spans created by pdt itself
+const tracerName = "github.com/donovanmods/projectdaedalus-db-tool"
+
+// shutdownTimeout bounds how long flushing spans may delay the exit
+const shutdownTimeout = 5 * time.Second
+
+var shutdown = func(context.Context) error { return nil }
+
+// Setup installs a global tracer provider that exports spans to dest:
+// "stdout", "file:<path>" or "otlp" (configured with the standard OTEL_EXPORTER_OTLP_* variables).
+// Tracing stays disabled when dest is empty.
+func Setup(ctx context.Context, dest, version string) error {
+	if dest == "" {
+		return nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+	switch {
+	case dest == Stdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case strings.HasPrefix(dest, FilePrefix):
+		var f *os.File
+		if f, err = os.OpenFile(strings.TrimPrefix(dest, FilePrefix), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600); err != nil {
+			return err
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	case dest == OTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return fmt.Errorf("unknown trace destination %q (expected %s, %s<path> or %s)", dest, Stdout, FilePrefix, OTLP)
+	}
+	if err != nil {
+		return err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("pdt"),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+
+	shutdown = func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Shutdown flushes any buffered spans and stops the exporter
+func Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return shutdown(ctx)
+}
+
+// Start starts a span as a child of any span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		// Spans leave the machine just like logs, so they get the same redaction
+		msg := logger.Redact(err.Error())
+		span.RecordError(errors.New(msg))
+		span.SetStatus(codes.Error, msg)
+	}
+	span.End()
+}
+
+// Transport wraps base so that every outbound HTTP request gets its own span
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
@@ -0,0 +1,113 @@
+package verify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+)
+
+// Store is a content-addressed directory of downloaded files, laid out as <dir>/sha256/<ab>/<digest>
+// where <ab> are the first two hex digits of the digest
+type Store struct {
+	dir string
+}
+
+// DefaultStoreDir returns the store location under the user's cache directory
+func DefaultStoreDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "pdt", "files"), nil
+}
+
+// OpenStore creates the store directory if needed
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "sha256"), 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// Path returns where the file with the given SHA-256 digest is kept
+func (s *Store) Path(digest string) string {
+	return filepath.Join(s.dir, "sha256", digest[:2], digest)
+}
+
+// Download streams url into the store, hashing it on the way, and returns its digest and size.
+// Downloads larger than maxSize are abandoned and nothing is stored.
+func (s *Store) Download(ctx context.Context, client *http.Client, url string, maxSize int64) (digest string, size int64, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("User-Agent", "pdt")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", 0, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	if resp.ContentLength > maxSize {
+		return "", 0, fmt.Errorf("%s is %d bytes, over the %d byte limit", url, resp.ContentLength, maxSize)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".download-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		tmp.Close()
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	h := sha256.New()
+	// One byte past the limit is enough to tell the file is too large
+	size, err = io.Copy(io.MultiWriter(tmp, h), io.LimitReader(resp.Body, maxSize+1))
+	metrics.Add(metrics.DownloadedBytes, float64(size))
+	if err != nil {
+		return "", size, err
+	}
+	if size > maxSize {
+		return "", size, fmt.Errorf("%s is over the %d byte limit", url, maxSize)
+	}
+	if err = tmp.Close(); err != nil {
+		return "", size, err
+	}
+
+	digest = hex.EncodeToString(h.Sum(nil))
+	path := s.Path(digest)
+	if _, statErr := os.Stat(path); statErr == nil {
+		// Already stored; the same content always has the same digest
+		err = os.Remove(tmp.Name())
+		return digest, size, err
+	} else if !errors.Is(statErr, fs.ErrNotExist) {
+		return "", size, statErr
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", size, err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", size, err
+	}
+
+	return digest, size, nil
+}
@@ -0,0 +1,176 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/logger"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/metrics"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/tracing"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// subsystem names this package in log records
+const subsystem = "verify"
+
+// Options controls how files are downloaded
+type Options struct {
+	// MaxSize is the largest file, in bytes, that is downloaded
+	MaxSize int64
+	// Concurrency is the number of files downloaded at once
+	Concurrency int
+	// Timeout bounds each download
+	Timeout time.Duration
+	// IDs limits verification to these mod documents; empty means every mod
+	IDs []string
+}
+
+// File is the outcome of verifying one file of a mod
+type File struct {
+	ID       string
+	Author   string
+	Name     string
+	Version  string
+	FileType string
+	URL      string
+	SHA256   string
+	Size     int64
+	// ChangedWithoutBump is set when the content changed since an earlier verification but the version did not
+	ChangedWithoutBump bool
+	Err                error
+}
+
+// Files downloads every file of every mod that is not blocked into store, records the SHA-256 digests
+// on the mod documents, and returns the outcome for each file
+func Files(ctx context.Context, store *Store, opts Options) (files []File, err error) {
+	ctx, span := tracing.Start(ctx, "verify.Files")
+	defer func() { tracing.End(span, err) }()
+
+	entries, err := firestore.Entries(ctx, models.KindMod)
+	if err != nil {
+		return nil, err
+	}
+
+	var mods []firestore.Entry
+	for _, e := range entries {
+		if e.Doc.Moderated().Blocked || (len(opts.IDs) > 0 && !slices.Contains(opts.IDs, e.ID)) {
+			continue
+		}
+		mods = append(mods, e)
+	}
+	for _, id := range opts.IDs {
+		if !slices.ContainsFunc(mods, func(e firestore.Entry) bool { return e.ID == id }) {
+			return nil, fmt.Errorf("no mod %q to verify", id)
+		}
+	}
+
+	for _, e := range mods {
+		mod := e.Doc.(*models.Mod)
+		for _, fileType := range slices.Sorted(maps.Keys(mod.Files)) {
+			files = append(files, File{ID: e.ID, Author: mod.Author, Name: mod.Name, Version: mod.Version, FileType: fileType, URL: mod.Files[fileType]})
+		}
+	}
+
+	download(ctx, store, opts, files)
+
+	// A mod that cannot be updated (e.g. synced meanwhile) does not stop the others from being recorded
+	dryrun := viper.GetBool("dryrun")
+	now := time.Now().UTC()
+	var errs []error
+	for _, e := range mods {
+		if err := record(ctx, e, files, now, dryrun); err != nil {
+			errs = append(errs, fmt.Errorf("mod %s: %w", e.ID, err))
+		}
+	}
+
+	return files, errors.Join(errs...)
+}
+
+// download fetches every file, opts.Concurrency at a time, filling in its digest and size or error
+func download(ctx context.Context, store *Store, opts Options, files []File) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	client := &http.Client{Timeout: opts.Timeout, Transport: tracing.Transport(http.DefaultTransport)}
+
+	queue := make(chan *File)
+	var wg sync.WaitGroup
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range queue {
+				ctx, span := tracing.Start(ctx, "verify.download", attribute.String("url", f.URL))
+				f.SHA256, f.Size, f.Err = store.Download(ctx, client, f.URL, opts.MaxSize)
+				tracing.End(span, f.Err)
+
+				if f.Err != nil {
+					metrics.Inc(metrics.FileVerifications, "result", "error")
+					logger.For(subsystem).Warn(fmt.Sprintf("Unable to download files.%s of mod %s: %v", f.FileType, f.ID, f.Err))
+					continue
+				}
+				logger.For(subsystem).Debug(fmt.Sprintf("Downloaded files.%s of mod %s: %d bytes, sha256 %s", f.FileType, f.ID, f.Size, f.SHA256))
+			}
+		}()
+	}
+
+	for i := range files {
+		queue <- &files[i]
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// record stores the digests of the downloaded files on a mod document and flags content that changed without a version bump
+func record(ctx context.Context, e firestore.Entry, files []File, now time.Time, dryrun bool) error {
+	before := e.Doc.(*models.Mod)
+	doc, err := models.Clone(before)
+	if err != nil {
+		return err
+	}
+	after := doc.(*models.Mod)
+
+	for i := range files {
+		f := &files[i]
+		if f.ID != e.ID || f.Err != nil {
+			continue
+		}
+
+		if after.FileInfo == nil {
+			after.FileInfo = make(map[string]models.FileInfo)
+		}
+		fi := after.FileInfo[f.FileType]
+		fi.RecordHash(f.SHA256, after.Version, now)
+		if fi.Size == nil {
+			fi.Size = &f.Size
+		}
+		after.FileInfo[f.FileType] = fi
+
+		f.ChangedWithoutBump = fi.ChangedWithoutBump
+		if f.ChangedWithoutBump {
+			metrics.Inc(metrics.FileVerifications, "result", "changed")
+			logger.For(subsystem).Warn(fmt.Sprintf("files.%s of mod %s changed without a version bump (still %s)", f.FileType, e.ID, after.Version))
+		} else {
+			metrics.Inc(metrics.FileVerifications, "result", "ok")
+		}
+	}
+
+	if models.Equal(before, after) {
+		return nil
+	}
+	if dryrun {
+		logger.For(subsystem).Info(fmt.Sprintf("Dry run: would record file hashes on mod %s", e.ID))
+		return nil
+	}
+
+	return firestore.UpdateEntry(ctx, models.KindMod, e.ID, before, after, e.UpdateTime)
+}
@@ -0,0 +1,182 @@
+package verify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/firestore"
+	"github.com/donovanmods/projectdaedalus-db-tool/lib/models"
+)
+
+func sum(content string) string {
+	h := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(h[:])
+}
+
+// serve answers every path with its content; chunked responses carry no Content-Length
+func serve(t *testing.T, files map[string]string, chunked bool) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if chunked {
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := OpenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+// leftovers returns the temporary download files left in the store
+func leftovers(t *testing.T, store *Store) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(store.dir, ".download-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return matches
+}
+
+func TestDownloadStopsAtSizeLimit(t *testing.T) {
+	big := strings.Repeat("x", 1024)
+
+	for _, chunked := range []bool{false, true} {
+		name := "with Content-Length"
+		if chunked {
+			name = "without Content-Length"
+		}
+		t.Run(name, func(t *testing.T) {
+			server := serve(t, map[string]string{"/big.zip": big}, chunked)
+			store := openStore(t)
+
+			_, _, err := store.Download(context.Background(), server.Client(), server.URL+"/big.zip", 512)
+			if err == nil || !strings.Contains(err.Error(), "512 byte limit") {
+				t.Fatalf("Download() error = %v, want the size limit", err)
+			}
+			if _, err := os.Stat(store.Path(sum(big))); err == nil {
+				t.Error("oversized file was stored")
+			}
+			if files := leftovers(t, store); len(files) > 0 {
+				t.Errorf("temporary files left behind: %v", files)
+			}
+		})
+	}
+}
+
+func TestDownloadStoresByDigestOnce(t *testing.T) {
+	content := "PK\x03\x04 mod contents"
+	server := serve(t, map[string]string{"/a.zip": content, "/copy.zip": content}, false)
+	store := openStore(t)
+	ctx := context.Background()
+
+	digest, size, err := store.Download(ctx, server.Client(), server.URL+"/a.zip", 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != sum(content) || size != int64(len(content)) {
+		t.Errorf("Download() = %s, %d; want %s, %d", digest, size, sum(content), len(content))
+	}
+
+	path := store.Path(digest)
+	if want := filepath.Join(store.dir, "sha256", digest[:2], digest); path != want {
+		t.Errorf("Path() = %s, want %s", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != content {
+		t.Fatalf("stored file = %q, %v; want the downloaded content", data, err)
+	}
+
+	// Mark the stored file so a rewrite would show
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	again, _, err := store.Download(ctx, server.Client(), server.URL+"/copy.zip", 1<<20)
+	if err != nil || again != digest {
+		t.Fatalf("second Download() = %s, %v; want %s", again, err, digest)
+	}
+	if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("stored file was written again")
+	}
+	if files := leftovers(t, store); len(files) > 0 {
+		t.Errorf("temporary files left behind: %v", files)
+	}
+}
+
+func TestDownloadReportsHTTPErrors(t *testing.T) {
+	server := serve(t, nil, false)
+	store := openStore(t)
+
+	if _, _, err := store.Download(context.Background(), server.Client(), server.URL+"/missing.zip", 1<<20); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Download() error = %v, want a 404", err)
+	}
+}
+
+func TestRecordReportsChangedContent(t *testing.T) {
+	server := serve(t, map[string]string{"/mod.zip": "new contents", "/mod.pak": "same contents"}, false)
+	store := openStore(t)
+
+	mod := &models.Mod{
+		Name:    "Better Stacks",
+		Author:  "jane",
+		Version: "1.0",
+		Files:   map[string]string{"zip": server.URL + "/mod.zip", "pak": server.URL + "/mod.pak"},
+		FileInfo: map[string]models.FileInfo{
+			"zip": {SHA256: sum("old contents"), VerifiedVersion: "1.0"},
+			"pak": {SHA256: sum("same contents"), VerifiedVersion: "1.0"},
+		},
+	}
+	entry := firestore.Entry{ID: "abc", Doc: mod}
+	files := []File{
+		{ID: "abc", FileType: "pak", URL: mod.Files["pak"]},
+		{ID: "abc", FileType: "zip", URL: mod.Files["zip"]},
+	}
+
+	download(context.Background(), store, Options{MaxSize: 1 << 20, Timeout: 5 * time.Second}, files)
+	if err := record(context.Background(), entry, files, time.Now(), true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		if f.Err != nil {
+			t.Fatalf("files.%s: %v", f.FileType, f.Err)
+		}
+	}
+	if files[0].ChangedWithoutBump {
+		t.Error("files.pak is unchanged but reported as changed")
+	}
+	if !files[1].ChangedWithoutBump || files[1].SHA256 != sum("new contents") {
+		t.Errorf("files.zip = %+v, want its new digest reported as changed without a version bump", files[1])
+	}
+	if mod.FileInfo["zip"].SHA256 != sum("old contents") {
+		t.Error("record modified the original document")
+	}
+}